@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/config"
+)
+
+// overrideFlags are the command line flags overriding settings of the
+// config file.
+type overrideFlags struct {
+	debug              *bool
+	port               *int
+	bindAddr           *string
+	disableLandingPage *bool
+	disableCache       *bool
+	cacheTTL           *string
+	cacheMaxSize       *int
+}
+
+func newOverrideFlags(fs *flag.FlagSet) *overrideFlags {
+	return &overrideFlags{
+		debug:              fs.Bool("debug", false, "Enable debug logging"),
+		port:               fs.Int("port", 0, "Server port (overrides config)"),
+		bindAddr:           fs.String("bind", "", "Bind address (overrides config)"),
+		disableLandingPage: fs.Bool("disable-landing-page", false, "Disable the landing page at root path (/)"),
+		disableCache:       fs.Bool("disable-cache", false, "Disable API response caching"),
+		cacheTTL:           fs.String("cache-ttl", "", "Cache TTL duration (e.g., 5m, 10m) (overrides config)"),
+		cacheMaxSize:       fs.Int("cache-max-size", 0, "Maximum number of cache entries (overrides config)"),
+	}
+}
+
+// apply overrides the settings of the flags set, also after reloads.
+func (f *overrideFlags) apply(cfg *config.Config) {
+	if *f.debug {
+		cfg.Logging.Level = "debug"
+		cfg.SetSource("logging.level", config.SourceFlag)
+	}
+	if *f.port > 0 {
+		cfg.Server.Port = *f.port
+		cfg.SetSource("server.port", config.SourceFlag)
+	}
+	if *f.bindAddr != "" {
+		cfg.Server.Host = *f.bindAddr
+		cfg.SetSource("server.host", config.SourceFlag)
+	}
+	if *f.disableLandingPage {
+		cfg.LandingPage.Enabled = false
+		cfg.SetSource("landing_page.enabled", config.SourceFlag)
+	}
+	if *f.disableCache {
+		cfg.ArtifactHub.Cache.Enabled = false
+		cfg.SetSource("artifacthub.cache.enabled", config.SourceFlag)
+	}
+	if *f.cacheTTL != "" {
+		if ttl, err := time.ParseDuration(*f.cacheTTL); err == nil {
+			cfg.ArtifactHub.Cache.TTL = ttl
+			cfg.SetSource("artifacthub.cache.ttl", config.SourceFlag)
+		} else {
+			logrus.Warnf("Invalid cache TTL duration %q, using config default", *f.cacheTTL)
+		}
+	}
+	if *f.cacheMaxSize > 0 {
+		cfg.ArtifactHub.Cache.MaxSize = *f.cacheMaxSize
+		cfg.SetSource("artifacthub.cache.max_size", config.SourceFlag)
+	}
+}
+
+// runConfigCommand implements the "config" subcommand and returns the
+// process exit code.
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Usage: tekton-hub-proxy config print [--config <file>] [--format yaml|json] [flags]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config print", flag.ExitOnError)
+	var (
+		configPath = fs.String("config", "", "Path to config file")
+		format     = fs.String("format", "yaml", "Output format: yaml or json")
+		overrides  = newOverrideFlags(fs)
+	)
+	_ = fs.Parse(args[1:])
+
+	cfg, err := config.LoadWithPath(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+	overrides.apply(cfg)
+
+	switch *format {
+	case "yaml":
+		out, err := cfg.EffectiveYAML()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		fmt.Printf("# Effective configuration of %s\n", config.FileUsed())
+		fmt.Print(string(out))
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg.Effective()); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "❌ Unknown format %q, expected yaml or json\n", *format)
+		return 2
+	}
+	return 0
+}
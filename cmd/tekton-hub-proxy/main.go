@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -10,24 +11,37 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/certs"
 	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/discovery"
 	"tekton-hub-proxy/internal/handlers"
+	"tekton-hub-proxy/internal/metrics"
 	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/mirror"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/snapshot"
 	"tekton-hub-proxy/internal/translator"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		os.Exit(runSnapshotCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(runValidateConfigCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
 	// Parse command line flags
 	var (
-		debug             = flag.Bool("debug", false, "Enable debug logging")
-		configPath        = flag.String("config", "", "Path to config file")
-		port              = flag.Int("port", 0, "Server port (overrides config)")
-		bindAddr          = flag.String("bind", "", "Bind address (overrides config)")
-		disableLandingPage = flag.Bool("disable-landing-page", false, "Disable the landing page at root path (/)")
-		disableCache      = flag.Bool("disable-cache", false, "Disable API response caching")
-		cacheTTL          = flag.String("cache-ttl", "", "Cache TTL duration (e.g., 5m, 10m) (overrides config)")
-		cacheMaxSize      = flag.Int("cache-max-size", 0, "Maximum number of cache entries (overrides config)")
-		help              = flag.Bool("help", false, "Show help message")
+		configPath   = flag.String("config", "", "Path to config file")
+		overrides    = newOverrideFlags(flag.CommandLine)
+		snapshotPath = flag.String("snapshot", "", "Serve from a snapshot archive instead of Artifact Hub")
+		watchConfig  = flag.Bool("watch-config", true, "Reload the config file when it changes")
+		help         = flag.Bool("help", false, "Show help message")
 	)
 	flag.Parse()
 
@@ -41,6 +55,11 @@ func main() {
 		flag.CommandLine.SetOutput(os.Stdout)
 		flag.PrintDefaults()
 		fmt.Println()
+		fmt.Println("Subcommands:")
+		fmt.Println("  snapshot export --output <file>   Write an offline snapshot of the mapped catalogs")
+		fmt.Println("  validate-config --config <file>   Check a config file and list every error")
+		fmt.Println("  config print --config <file>      Show the effective configuration and its sources")
+		fmt.Println()
 		fmt.Println("Configuration:")
 		fmt.Println("  Cache settings can be configured via config file:")
 		fmt.Println("  artifacthub:")
@@ -63,55 +82,143 @@ func main() {
 		logrus.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Override config with command line flags
-	if *debug {
-		cfg.Logging.Level = "debug"
-	}
-	if *port > 0 {
-		cfg.Server.Port = *port
-	}
-	if *bindAddr != "" {
-		cfg.Server.Host = *bindAddr
-	}
-	if *disableLandingPage {
-		cfg.LandingPage.Enabled = false
-	}
-	if *disableCache {
-		cfg.ArtifactHub.Cache.Enabled = false
-	}
-	if *cacheTTL != "" {
-		if ttl, err := time.ParseDuration(*cacheTTL); err == nil {
-			cfg.ArtifactHub.Cache.TTL = ttl
-		} else {
-			logrus.Warnf("Invalid cache TTL duration %q, using config default", *cacheTTL)
-		}
-	}
-	if *cacheMaxSize > 0 {
-		cfg.ArtifactHub.Cache.MaxSize = *cacheMaxSize
+	// Command line flags override the config file, also after reloads
+	overrides.apply(cfg)
+	if err := validateConfig(cfg); err != nil {
+		logrus.Fatalf("Invalid configuration: %v", err)
 	}
 
 	// Setup logging
 	setupLogging(cfg.Logging)
 
-	logrus.WithField("config", cfg).Info("Starting Tekton Hub Proxy")
+	logrus.WithField("config_file", config.FileUsed()).Info("Starting Tekton Hub Proxy")
+	logrus.WithField("config", cfg.Effective().Config).Debug("Effective configuration")
 
-	// Create Artifact Hub client
-	artifactHubClient := client.NewArtifactHubClient(cfg.ArtifactHub)
+	// Kinds, version policy and categories shared by every translator
+	kinds := translator.NewKindTable(cfg.Kinds)
+	categories := translator.NewCategoryTable(cfg.Categories)
+	versionTranslator, err := translator.NewVersionTranslatorWithPolicy(cfg.Versions.SimplifiedPolicy)
+	if err != nil {
+		logrus.Fatalf("Invalid versions configuration: %v", err)
+	}
+	aliases, err := translator.NewAliasTable(cfg.Aliases, versionTranslator)
+	if err != nil {
+		logrus.Fatalf("Invalid aliases: %v", err)
+	}
+	resourcePolicy, err := policy.NewEngine(cfg.Policy, versionTranslator)
+	if err != nil {
+		logrus.Fatalf("Invalid policy: %v", err)
+	}
+
+	// Create the package source: Artifact Hub, or an offline snapshot
+	var packageSource client.PackageSource
+	var catalogMirror *mirror.Mirror
+	var artifactHubClient *client.ArtifactHubClient
+	if *snapshotPath != "" {
+		store, err := snapshot.Load(*snapshotPath)
+		if err != nil {
+			logrus.Fatalf("Failed to load snapshot: %v", err)
+		}
+		meta := store.Metadata()
+		if len(cfg.CatalogMappings) == 0 {
+			cfg.CatalogMappings = meta.CatalogMappings
+			cfg.SetSource("catalog_mappings", config.SourceSnapshot)
+		}
+		logrus.WithFields(logrus.Fields{
+			"snapshot":   *snapshotPath,
+			"created_at": meta.CreatedAt,
+			"source":     meta.Source,
+			"packages":   meta.Packages,
+			"versions":   meta.Versions,
+		}).Info("Serving from snapshot, Artifact Hub will not be contacted")
+		packageSource = store
+	} else if cfg.Mirror.Enabled {
+		// The mirror is the cache, only the sync loop talks to Artifact Hub.
+		upstreamConfig := cfg.ArtifactHub
+		upstreamConfig.Cache.Enabled = false
+		crawler := snapshot.NewCrawler(
+			client.NewArtifactHubClient(upstreamConfig),
+			translator.NewCatalogTranslator(cfg.CatalogMappings, kinds),
+			translator.NewResponseTranslator(kinds, versionTranslator, categories),
+		)
+		catalogMirror = mirror.NewMirror(crawler, cfg.ArtifactHub.BaseURL, cfg.CatalogMappings, cfg.Mirror)
+		catalogMirror.Start()
+		logrus.WithFields(logrus.Fields{
+			"interval": cfg.Mirror.Interval,
+			"path":     cfg.Mirror.Path,
+		}).Info("Mirror mode enabled, serving from the local store")
+		packageSource = catalogMirror
+	} else {
+		artifactHubClient = client.NewArtifactHubClient(cfg.ArtifactHub)
+		packageSource = artifactHubClient
+	}
 
 	// Create translator
-	catalogTranslator := translator.NewCatalogTranslator(cfg.CatalogMappings)
-	responseTranslator := translator.NewResponseTranslator()
-	versionTranslator := translator.NewVersionTranslator()
+	catalogTranslator := translator.NewCatalogTranslator(cfg.CatalogMappings, kinds)
+	responseTranslator := translator.NewResponseTranslator(kinds, versionTranslator, categories)
+
+	// Create backends, catalogs not mapped elsewhere are served by Artifact Hub
+	backendSet := newBackendSet(packageSource, responseTranslator)
+	backends, err := backendSet.registry(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to setup backends: %v", err)
+	}
 
 	// Create handlers
 	handlers := handlers.NewHandlers(
-		artifactHubClient,
+		backends,
 		catalogTranslator,
 		responseTranslator,
 		versionTranslator,
+		aliases,
+		resourcePolicy,
 		cfg,
 	)
 
+	if catalogMirror != nil {
+		handlers.AddHealthReporter("mirror", catalogMirror)
+		handlers.AddReadinessCheck("mirror", catalogMirror)
+	}
+
+	// Discovered catalogs are served by Artifact Hub directly, snapshots
+	// and mirrors only hold the configured ones
+	var discoverer *discovery.Discoverer
+	if cfg.Discovery.Enabled {
+		if artifactHubClient == nil {
+			logrus.Warn("Catalog discovery is not supported with snapshots or mirror mode, ignoring")
+		} else {
+			discoverer = discovery.NewDiscoverer(artifactHubClient, kinds, cfg.Discovery, cfg.CatalogMappings, func(mappings []config.CatalogMapping) {
+				handlers.SetCatalogTranslator(translator.NewCatalogTranslator(mappings, kinds))
+			})
+			handlers.SetDiscovery(discoverer)
+			handlers.AddHealthReporter("discovery", discoverer)
+			discoverer.Start()
+			logrus.WithFields(logrus.Fields{
+				"interval":      cfg.Discovery.Interval,
+				"organizations": cfg.Discovery.Organizations,
+				"users":         cfg.Discovery.Users,
+			}).Info("Catalog discovery enabled")
+		}
+	}
+
+	if *watchConfig {
+		configReloader := &reloader{
+			current:           cfg,
+			overrides:         overrides.apply,
+			fixedMappings:     artifactHubClient == nil,
+			kinds:             kinds,
+			versionTranslator: versionTranslator,
+			backends:          backendSet,
+			handlers:          handlers,
+			discoverer:        discoverer,
+		}
+		if artifactHubClient != nil {
+			configReloader.client = artifactHubClient
+		}
+		config.Watch(configReloader.reload)
+		logrus.Info("Watching the config file for changes")
+	}
+
 	// Setup routes
 	router := setupRoutes(handlers, cfg)
 
@@ -123,7 +230,10 @@ func main() {
 
 	// Start server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-	logrus.WithField("address", addr).Info("Server starting")
+	logrus.WithFields(logrus.Fields{
+		"address": addr,
+		"tls":     cfg.Server.TLS.Enabled,
+	}).Info("Server starting")
 
 	server := &http.Server{
 		Addr:         addr,
@@ -133,9 +243,78 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	if err := server.ListenAndServe(); err != nil {
+	// Certificates are reloaded when cert-manager or others rotate them
+	var certReloader *certs.Reloader
+	if cfg.Server.TLS.Enabled {
+		certReloader, err = certs.NewReloader(cfg.Server.TLS)
+		if err != nil {
+			logrus.Fatalf("Failed to load TLS certificate: %v", err)
+		}
+		if err := certReloader.Start(); err != nil {
+			logrus.Fatalf("Failed to watch TLS certificate: %v", err)
+		}
+		server.TLSConfig = certReloader.TLSConfig()
+		handlers.AddHealthReporter("tls", certReloader)
+		logrus.WithFields(logrus.Fields{
+			"cert_file":      cfg.Server.TLS.CertFile,
+			"client_ca_file": cfg.Server.TLS.ClientCAFile,
+			"min_version":    cfg.Server.TLS.MinVersion,
+		}).Info("TLS enabled")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
 		logrus.Fatalf("Server failed to start: %v", err)
 	}
+	serve(server, listener, cfg.Server, handlers, shutdownSignals(), func() {
+		// A mirror sync in progress finishes and persists its copy
+		if catalogMirror != nil {
+			catalogMirror.Stop()
+		}
+		if discoverer != nil {
+			discoverer.Stop()
+		}
+		backendSet.close()
+		if artifactHubClient != nil {
+			_ = artifactHubClient.Close()
+		}
+		if certReloader != nil {
+			certReloader.Stop()
+		}
+	})
+}
+
+// newBackend creates the backend of a target, or nil when the target is
+// served by the default Artifact Hub backend.
+func newBackend(target config.CatalogTarget, artifactHubConfig config.ArtifactHubConfig, responseTranslator *translator.ResponseTranslator) (backend.Backend, error) {
+	switch target.BackendName() {
+	case config.BackendArtifactHub:
+		if target.BaseURL == "" {
+			return nil, nil
+		}
+		instanceConfig := artifactHubConfig
+		instanceConfig.BaseURL = target.BaseURL
+		return backend.NewArtifactHub(client.NewArtifactHubClient(instanceConfig)), nil
+	case config.BackendFilesystem:
+		if target.Path == "" {
+			return nil, fmt.Errorf("filesystem backend requires a path")
+		}
+		return backend.NewFilesystem(target.ArtifactHub, target.Path, responseTranslator), nil
+	case config.BackendGit:
+		if target.URL == "" {
+			return nil, fmt.Errorf("git backend requires a url")
+		}
+		return backend.NewGit(target.ArtifactHub, backend.GitOptions{
+			URL:             target.URL,
+			Ref:             target.Ref,
+			Versions:        target.Versions,
+			RefreshInterval: target.RefreshInterval,
+			Timeout:         target.Timeout,
+			Dir:             target.Path,
+		}, responseTranslator)
+	default:
+		return nil, fmt.Errorf("unknown backend %q", target.Backend)
+	}
 }
 
 func setupLogging(cfg config.LoggingConfig) {
@@ -165,9 +344,11 @@ func setupRoutes(h *handlers.Handlers, cfg *config.Config) *mux.Router {
 
 	// Catalog endpoints
 	router.HandleFunc("/v1/catalogs", h.ListCatalogs).Methods("GET")
+	router.HandleFunc("/v1/categories", h.ListCategories).Methods("GET")
 
 	// Resource endpoints (order matters - more specific routes first)
 	router.HandleFunc("/v1/resource/{catalog}/{kind}/{name}/raw", h.GetLatestResourceYAML).Methods("GET")
+	router.HandleFunc("/v1/resource/{catalog}/{kind}/{name}/versions", h.GetResourceVersions).Methods("GET")
 	router.HandleFunc("/v1/resource/{catalog}/{kind}/{name}/{version}/yaml", h.GetResourceYAML).Methods("GET")
 	router.HandleFunc("/v1/resource/{catalog}/{kind}/{name}/{version}/readme", h.GetResourceReadme).Methods("GET")
 	router.HandleFunc("/v1/resource/{catalog}/{kind}/{name}/{version}/raw", h.GetResourceYAMLRaw).Methods("GET")
@@ -183,6 +364,13 @@ func setupRoutes(h *handlers.Handlers, cfg *config.Config) *mux.Router {
 
 	// Health check
 	router.HandleFunc("/health", h.HealthCheck).Methods("GET")
+	router.HandleFunc("/ready", h.Readiness).Methods("GET")
+	// Admin endpoints (opt-in)
+	if cfg.Server.Admin.Enabled {
+		router.HandleFunc("/admin/discovery", h.GetDiscoveredMappings).Methods("GET")
+		router.HandleFunc("/admin/config", h.GetEffectiveConfig).Methods("GET")
+	}
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
 
 	return router
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/handlers"
+)
+
+func TestSetupRoutes_Admin(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		expected int
+	}{
+		{name: "disabled by default", expected: http.StatusNotFound},
+		{name: "enabled", enabled: true, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{Admin: config.AdminConfig{Enabled: tt.enabled}}}
+			h := handlers.NewHandlers(nil, nil, nil, nil, nil, nil, cfg)
+			router := setupRoutes(h, cfg)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+			if recorder.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, recorder.Code)
+			}
+		})
+	}
+}
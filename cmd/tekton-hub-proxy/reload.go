@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/discovery"
+	"tekton-hub-proxy/internal/metrics"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/translator"
+)
+
+var configReloads = metrics.NewCounterVec(
+	"tekton_hub_proxy_config_reloads_total",
+	"Configuration reloads by result.",
+	"result",
+)
+
+// reloadableSections are the configuration sections a reload applies, the
+// others keep their values until the next restart.
+var reloadableSections = map[string]bool{
+	"artifacthub.cache.ttl": true,
+	"catalog_mappings":      true,
+	"logging.level":         true,
+	"aliases":               true,
+	"policy":                true,
+	"strict_catalogs":       true,
+}
+
+// backendSet builds the backends of the catalog targets. Backends of
+// targets unchanged since the previous build are reused, so reloads don't
+// clone git catalogs again.
+type backendSet struct {
+	packageSource      client.PackageSource
+	responseTranslator *translator.ResponseTranslator
+	newBackend         func(config.CatalogTarget, config.ArtifactHubConfig, *translator.ResponseTranslator) (backend.Backend, error)
+
+	mutex sync.Mutex
+	built map[string]backend.Backend
+}
+
+func newBackendSet(packageSource client.PackageSource, responseTranslator *translator.ResponseTranslator) *backendSet {
+	return &backendSet{
+		packageSource:      packageSource,
+		responseTranslator: responseTranslator,
+		newBackend:         newBackend,
+		built:              make(map[string]backend.Backend),
+	}
+}
+
+// registry returns the backends of the mappings, catalogs not mapped
+// elsewhere are served by the package source.
+func (s *backendSet) registry(cfg *config.Config) (*backend.Registry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registry := backend.NewRegistry(backend.NewArtifactHub(s.packageSource))
+	built := make(map[string]backend.Backend)
+
+	for _, mapping := range cfg.CatalogMappings {
+		if mapping.Pattern != "" {
+			// Matched catalogs are served by the default backend
+			continue
+		}
+		for _, target := range mapping.AllTargets() {
+			key := fmt.Sprintf("%+v", target)
+			b, reused := s.built[key]
+			if !reused {
+				var err error
+				b, err = s.newBackend(target, cfg.ArtifactHub, s.responseTranslator)
+				if err != nil {
+					return nil, fmt.Errorf("catalog %q: %w", mapping.TektonHub, err)
+				}
+			}
+			if b == nil {
+				continue
+			}
+			if reused {
+				// Artifact Hub instances cache with the configured TTL
+				setCacheTTL(b, cfg.ArtifactHub.Cache.TTL)
+			}
+			built[key] = b
+			registry.Register(target.ArtifactHub, b)
+			if !reused {
+				logrus.WithFields(logrus.Fields{
+					"catalog": mapping.TektonHub,
+					"target":  target.ArtifactHub,
+					"backend": target.BackendName(),
+				}).Info("Registered catalog backend")
+			}
+		}
+	}
+
+	// Backends of removed targets stop their background work
+	for key, b := range s.built {
+		if _, kept := built[key]; !kept {
+			closeBackend(b)
+		}
+	}
+	s.built = built
+	return registry, nil
+}
+
+// close stops the background work of the built backends.
+func (s *backendSet) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, b := range s.built {
+		closeBackend(b)
+	}
+}
+
+// setCacheTTL changes the cache TTL of backends caching responses.
+func setCacheTTL(b backend.Backend, ttl time.Duration) {
+	if cached, ok := b.(interface{ SetCacheTTL(time.Duration) }); ok {
+		cached.SetCacheTTL(ttl)
+	}
+}
+
+func closeBackend(b backend.Backend) {
+	if closer, ok := b.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			logrus.WithError(err).Warn("Failed to close catalog backend")
+		}
+	}
+}
+
+// reconfigurable is what a reload swaps the components of a configuration
+// into, the handlers.
+type reconfigurable interface {
+	Reconfigure(config *config.Config, catalogTranslator *translator.CatalogTranslator, backends *backend.Registry, aliases *translator.AliasTable, policy *policy.Engine)
+}
+
+// cacheClient is an Artifact Hub client whose cache TTL a reload changes.
+type cacheClient interface {
+	SetCacheTTL(ttl time.Duration)
+}
+
+// reloader applies changes of the config file to the running proxy. The
+// whole file is validated before anything is swapped in, so an invalid file
+// keeps the current configuration.
+type reloader struct {
+	mutex   sync.Mutex
+	current *config.Config
+	// overrides reapplies the command line flags to reloaded files
+	overrides func(*config.Config)
+	// fixedMappings is set when serving snapshots or mirrors, which only
+	// hold the catalogs configured at startup
+	fixedMappings bool
+
+	kinds             *translator.KindTable
+	versionTranslator *translator.VersionTranslator
+	backends          *backendSet
+	handlers          reconfigurable
+	client            cacheClient
+	discoverer        *discovery.Discoverer
+}
+
+// reload applies the reloadable sections of a reloaded configuration.
+func (r *reloader) reload(next *config.Config, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err != nil {
+		r.reject(err)
+		return
+	}
+	r.overrides(next)
+	if err := validateConfig(next); err != nil {
+		r.reject(err)
+		return
+	}
+	// The cache can't be turned off without a restart, so the running
+	// caches need a positive TTL whatever the reloaded file enables
+	if r.current.ArtifactHub.Cache.Enabled && next.ArtifactHub.Cache.TTL <= 0 {
+		r.reject(fmt.Errorf("artifacthub.cache.ttl: must be positive while the cache is enabled, got %s", next.ArtifactHub.Cache.TTL))
+		return
+	}
+
+	changes := config.Diff(r.current, next)
+	if len(changes) == 0 {
+		logrus.Debug("Config file changed without changes to apply")
+		return
+	}
+
+	var applied, restart []string
+	for _, change := range changes {
+		if reloadableSections[change.Section] && !(change.Section == "catalog_mappings" && r.fixedMappings) {
+			applied = append(applied, change.String())
+		} else {
+			restart = append(restart, change.Section)
+		}
+	}
+
+	// Sections needing a restart keep their current values
+	updated := *r.current
+	updated.ArtifactHub.Cache.TTL = next.ArtifactHub.Cache.TTL
+	if !r.fixedMappings {
+		updated.CatalogMappings = next.CatalogMappings
+	}
+	updated.Logging.Level = next.Logging.Level
+	updated.Aliases = next.Aliases
+	updated.Policy = next.Policy
+	updated.StrictCatalogs = next.StrictCatalogs
+	var reloaded []string
+	for section := range reloadableSections {
+		if section != "catalog_mappings" || !r.fixedMappings {
+			reloaded = append(reloaded, section)
+		}
+	}
+	updated.Sources = r.current.Sources.Update(next.Sources, reloaded...)
+
+	if err := r.apply(&updated); err != nil {
+		r.reject(err)
+		return
+	}
+	r.current = &updated
+	configReloads.Inc("success")
+
+	if len(restart) > 0 {
+		logrus.WithField("sections", restart).Warn("Configuration changes need a restart to apply")
+	}
+	if len(applied) > 0 {
+		logrus.WithField("changes", strings.Join(applied, "; ")).Info("🔄 Configuration reloaded")
+	}
+}
+
+// apply builds the components of a validated configuration and swaps them
+// in.
+func (r *reloader) apply(cfg *config.Config) error {
+	level, err := logrus.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		return fmt.Errorf("invalid log level %q", cfg.Logging.Level)
+	}
+	aliases, err := translator.NewAliasTable(cfg.Aliases, r.versionTranslator)
+	if err != nil {
+		return fmt.Errorf("invalid aliases: %w", err)
+	}
+	resourcePolicy, err := policy.NewEngine(cfg.Policy, r.versionTranslator)
+	if err != nil {
+		return fmt.Errorf("invalid policy: %w", err)
+	}
+	backends, err := r.backends.registry(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to setup backends: %w", err)
+	}
+
+	// Discovery merges changed mappings with the discovered ones and swaps
+	// in their translator itself
+	var catalogTranslator *translator.CatalogTranslator
+	mappingsChanged := !reflect.DeepEqual(cfg.CatalogMappings, r.current.CatalogMappings)
+	if mappingsChanged && r.discoverer == nil {
+		catalogTranslator = translator.NewCatalogTranslator(cfg.CatalogMappings, r.kinds)
+	}
+	r.handlers.Reconfigure(cfg, catalogTranslator, backends, aliases, resourcePolicy)
+	if mappingsChanged && r.discoverer != nil {
+		r.discoverer.SetConfigured(cfg.CatalogMappings)
+	}
+	if r.client != nil {
+		r.client.SetCacheTTL(cfg.ArtifactHub.Cache.TTL)
+	}
+	logrus.SetLevel(level)
+	return nil
+}
+
+func (r *reloader) reject(err error) {
+	configReloads.Inc("rejected")
+	logrus.WithError(err).Error("❌ Configuration reload rejected, keeping the current configuration")
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/translator"
+)
+
+// fakeBackend records what the backend set does with it.
+type fakeBackend struct {
+	backend.Backend
+	ttl    time.Duration
+	closed bool
+}
+
+func (b *fakeBackend) SetCacheTTL(ttl time.Duration) {
+	b.ttl = ttl
+}
+
+func (b *fakeBackend) Close() error {
+	b.closed = true
+	return nil
+}
+
+// fakeHandlers records the settings swapped in.
+type fakeHandlers struct {
+	reconfigured      int
+	config            *config.Config
+	catalogTranslator *translator.CatalogTranslator
+	backends          *backend.Registry
+}
+
+func (h *fakeHandlers) Reconfigure(config *config.Config, catalogTranslator *translator.CatalogTranslator, backends *backend.Registry, aliases *translator.AliasTable, policy *policy.Engine) {
+	h.reconfigured++
+	h.config, h.catalogTranslator, h.backends = config, catalogTranslator, backends
+}
+
+type fakeClient struct {
+	ttl time.Duration
+}
+
+func (c *fakeClient) SetCacheTTL(ttl time.Duration) {
+	c.ttl = ttl
+}
+
+func reloadConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{Port: 8080, Host: "0.0.0.0", ShutdownTimeout: 30 * time.Second},
+		ArtifactHub: config.ArtifactHubConfig{
+			BaseURL:    "https://artifacthub.io",
+			Timeout:    30 * time.Second,
+			MaxRetries: 3,
+			Cache:      config.CacheConfig{Enabled: true, TTL: time.Hour, MaxSize: 2000},
+		},
+		CatalogMappings: []config.CatalogMapping{
+			{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+			{TektonHub: "internal", Targets: []config.CatalogTarget{
+				{ArtifactHub: "internal-tasks", BaseURL: "https://hub.example.com"},
+			}},
+		},
+		Logging:    config.LoggingConfig{Level: "info", Format: "json"},
+		Kinds:      config.DefaultKinds(),
+		Categories: config.DefaultCategories(),
+		Policy:     config.PolicyConfig{Default: config.PolicyAllow},
+	}
+}
+
+// newFakeBackendSet returns a backend set building fake backends, and the
+// backends it built by catalog.
+func newFakeBackendSet() (*backendSet, map[string]*fakeBackend) {
+	built := make(map[string]*fakeBackend)
+	set := newBackendSet(nil, nil)
+	set.newBackend = func(target config.CatalogTarget, _ config.ArtifactHubConfig, _ *translator.ResponseTranslator) (backend.Backend, error) {
+		if target.BaseURL == "" && target.BackendName() == config.BackendArtifactHub {
+			return nil, nil
+		}
+		b := &fakeBackend{}
+		built[target.ArtifactHub] = b
+		return b, nil
+	}
+	return set, built
+}
+
+func TestReloader_Reload(t *testing.T) {
+	tests := []struct {
+		name          string
+		fixedMappings bool
+		loadErr       error
+		modify        func(*config.Config)
+		reconfigured  bool
+		check         func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend)
+	}{
+		{
+			name:    "unreadable file",
+			loadErr: errors.New("yaml: line 3: did not find expected key"),
+			modify:  func(c *config.Config) { c.Logging.Level = "debug" },
+		},
+		{
+			name:   "invalid file",
+			modify: func(c *config.Config) { c.Logging.Level = "debug"; c.Policy.Default = "maybe" },
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if r.current.Logging.Level != "info" {
+					t.Errorf("expected the current config to be kept, got level %s", r.current.Logging.Level)
+				}
+			},
+		},
+		{
+			name:   "no changes",
+			modify: func(c *config.Config) {},
+		},
+		{
+			name: "reloadable sections",
+			modify: func(c *config.Config) {
+				c.Logging.Level = "debug"
+				c.Policy.Default = config.PolicyDeny
+				c.StrictCatalogs = true
+			},
+			reconfigured: true,
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if h.config.Policy.Default != config.PolicyDeny || !h.config.StrictCatalogs || r.current != h.config {
+					t.Errorf("expected the reloaded sections to be applied, got %+v", h.config)
+				}
+				if logrus.GetLevel() != logrus.DebugLevel {
+					t.Errorf("expected log level debug, got %s", logrus.GetLevel())
+				}
+				if h.catalogTranslator != nil {
+					t.Error("expected the catalog translator to be kept with unchanged mappings")
+				}
+			},
+		},
+		{
+			name: "sections needing a restart",
+			modify: func(c *config.Config) {
+				c.Server.Port = 9090
+				c.ArtifactHub.BaseURL = "https://hub.example.com"
+				c.Logging.Level = "debug"
+			},
+			reconfigured: true,
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if h.config.Server.Port != 8080 || h.config.ArtifactHub.BaseURL != "https://artifacthub.io" {
+					t.Errorf("expected the server and artifacthub sections to be kept, got %+v", h.config)
+				}
+				if h.config.Logging.Level != "debug" {
+					t.Errorf("expected the log level to be reloaded, got %s", h.config.Logging.Level)
+				}
+			},
+		},
+		{
+			name: "catalog mappings",
+			modify: func(c *config.Config) {
+				c.CatalogMappings = append(c.CatalogMappings, config.CatalogMapping{TektonHub: "community", ArtifactHub: "community-tasks"})
+			},
+			reconfigured: true,
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if h.catalogTranslator == nil || len(r.current.CatalogMappings) != 3 {
+					t.Error("expected the mappings and their translator to be swapped in")
+				}
+				if _, err := h.catalogTranslator.TektonToArtifactHub("community"); err != nil {
+					t.Errorf("expected the added catalog to be mapped: %v", err)
+				}
+			},
+		},
+		{
+			name:          "fixed catalog mappings",
+			fixedMappings: true,
+			modify: func(c *config.Config) {
+				c.CatalogMappings = c.CatalogMappings[:1]
+				c.Logging.Level = "debug"
+			},
+			reconfigured: true,
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if h.catalogTranslator != nil || len(r.current.CatalogMappings) != 2 {
+					t.Error("expected the mappings of the snapshot to be kept")
+				}
+				if built["internal-tasks"].closed {
+					t.Error("expected the backend of the kept mapping to stay open")
+				}
+			},
+		},
+		{
+			name:         "cache ttl",
+			modify:       func(c *config.Config) { c.ArtifactHub.Cache.TTL = 10 * time.Minute },
+			reconfigured: true,
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if c.ttl != 10*time.Minute || built["internal-tasks"].ttl != 10*time.Minute {
+					t.Errorf("expected every client to cache for 10m, got %s and %s", c.ttl, built["internal-tasks"].ttl)
+				}
+			},
+		},
+		{
+			name: "cache ttl zero with the cache disabled",
+			modify: func(c *config.Config) {
+				c.ArtifactHub.Cache.Enabled = false
+				c.ArtifactHub.Cache.TTL = 0
+			},
+			check: func(t *testing.T, r *reloader, h *fakeHandlers, c *fakeClient, built map[string]*fakeBackend) {
+				if c.ttl != 0 || r.current.ArtifactHub.Cache.TTL != time.Hour {
+					t.Errorf("expected the running cache to keep its ttl, got %s", r.current.ArtifactHub.Cache.TTL)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer logrus.SetLevel(logrus.GetLevel())
+
+			current := reloadConfig()
+			kinds := translator.NewKindTable(current.Kinds)
+			backends, built := newFakeBackendSet()
+			if _, err := backends.registry(current); err != nil {
+				t.Fatal(err)
+			}
+			h, c := &fakeHandlers{}, &fakeClient{}
+			r := &reloader{
+				current:           current,
+				overrides:         func(*config.Config) {},
+				fixedMappings:     tt.fixedMappings,
+				kinds:             kinds,
+				versionTranslator: translator.NewVersionTranslator(),
+				backends:          backends,
+				handlers:          h,
+				client:            c,
+			}
+
+			next := reloadConfig()
+			tt.modify(next)
+			r.reload(next, tt.loadErr)
+
+			if reconfigured := h.reconfigured == 1; reconfigured != tt.reconfigured {
+				t.Errorf("expected reconfigured %v, got %d reconfigurations", tt.reconfigured, h.reconfigured)
+			}
+			if !tt.reconfigured && r.current != current {
+				t.Error("expected the current config to be kept")
+			}
+			if tt.check != nil {
+				tt.check(t, r, h, c, built)
+			}
+		})
+	}
+}
+
+func TestBackendSet_Registry(t *testing.T) {
+	set, built := newFakeBackendSet()
+	cfg := reloadConfig()
+	cfg.CatalogMappings = append(cfg.CatalogMappings, config.CatalogMapping{TektonHub: "local", Targets: []config.CatalogTarget{
+		{ArtifactHub: "local-tasks", Backend: config.BackendFilesystem, Path: "/catalog"},
+	}})
+	registry, err := set.registry(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal, local := built["internal-tasks"], built["local-tasks"]
+	if registry.For("internal-tasks") != internal || registry.For("local-tasks") != local {
+		t.Fatal("expected the targets to be served by their backends")
+	}
+
+	// The filesystem target moves, the Artifact Hub instance is unchanged
+	next := reloadConfig()
+	next.ArtifactHub.Cache.TTL = time.Minute
+	next.CatalogMappings = append(next.CatalogMappings, config.CatalogMapping{TektonHub: "local", Targets: []config.CatalogTarget{
+		{ArtifactHub: "local-tasks", Backend: config.BackendFilesystem, Path: "/srv/catalog"},
+	}})
+	registry, err = set.registry(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry.For("internal-tasks") != internal || internal.closed || internal.ttl != time.Minute {
+		t.Error("expected the unchanged backend to be reused with the reloaded ttl")
+	}
+	if !local.closed || registry.For("local-tasks") != built["local-tasks"] || built["local-tasks"] == local {
+		t.Error("expected the changed target to get a new backend and the previous one to be closed")
+	}
+
+	set.close()
+	if !internal.closed || !built["local-tasks"].closed {
+		t.Error("expected close to close every backend")
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/handlers"
+)
+
+// shutdownSignals returns the channel receiving SIGTERM and SIGINT.
+func shutdownSignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	return signals
+}
+
+// serve runs the server on listener, over TLS when it has a TLS
+// configuration, until a signal arrives. It then fails readiness, stops
+// accepting connections, drains the requests in flight and calls stop to
+// end the background work, all within the shutdown timeout.
+func serve(server *http.Server, listener net.Listener, cfg config.ServerConfig, h *handlers.Handlers, signals <-chan os.Signal, stop func()) {
+	serverErr := make(chan error, 1)
+	go func() {
+		if server.TLSConfig != nil {
+			// Certificates come from the TLS configuration
+			serverErr <- server.ServeTLS(listener, "", "")
+			return
+		}
+		serverErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serverErr:
+		logrus.Fatalf("Server failed to start: %v", err)
+	case sig := <-signals:
+		logrus.WithFields(logrus.Fields{
+			"signal":  sig.String(),
+			"delay":   cfg.ShutdownDelay,
+			"timeout": cfg.ShutdownTimeout,
+		}).Info("🛑 Shutting down, draining requests in flight")
+	}
+
+	h.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logrus.WithError(err).Warn("Requests still in flight at the shutdown timeout, closing their connections")
+		_ = server.Close()
+	}
+	if err := <-serverErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logrus.WithError(err).Warn("Server stopped with an error")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		logrus.Info("👋 Shutdown complete")
+	case <-ctx.Done():
+		logrus.Warn("Background work still running at the shutdown timeout, exiting")
+	}
+}
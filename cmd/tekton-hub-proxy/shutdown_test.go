@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/handlers"
+)
+
+// events records the order of the shutdown steps.
+type events struct {
+	mutex sync.Mutex
+	names []string
+}
+
+func (e *events) add(name string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.names = append(e.names, name)
+}
+
+func (e *events) list() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return append([]string(nil), e.names...)
+}
+
+// startServe runs serve on a test listener with a handler blocking until
+// release is closed, and returns the URL served and a channel closed when
+// serve returns.
+func startServe(t *testing.T, cfg config.ServerConfig, h *handlers.Handlers, signals <-chan os.Signal, started chan<- struct{}, release <-chan struct{}, stop func()) (string, <-chan struct{}) {
+	t.Helper()
+	unstarted := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	server := &http.Server{Handler: unstarted.Config.Handler}
+
+	done := make(chan struct{})
+	go func() {
+		serve(server, unstarted.Listener, cfg, h, signals, stop)
+		close(done)
+	}()
+	return "http://" + unstarted.Listener.Addr().String(), done
+}
+
+func TestServe_DrainsRequestsBeforeStopping(t *testing.T) {
+	h := handlers.NewHandlers(nil, nil, nil, nil, nil, nil, nil)
+	signals := make(chan os.Signal, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	recorded := &events{}
+	cfg := config.ServerConfig{ShutdownDelay: 50 * time.Millisecond, ShutdownTimeout: 5 * time.Second}
+
+	url, done := startServe(t, cfg, h, signals, started, release, func() { recorded.add("stop") })
+
+	requestErr := make(chan error, 1)
+	go func() {
+		response, err := http.Get(url + "/slow")
+		if err == nil {
+			response.Body.Close()
+			recorded.add("request")
+		}
+		requestErr <- err
+	}()
+	<-started
+
+	signals <- syscall.SIGTERM
+	deadline := time.Now().Add(time.Second)
+	for serveReadiness(h) != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("expected readiness to fail after the signal")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("expected serve to wait for the request in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-requestErr; err != nil {
+		t.Fatalf("expected the request in flight to complete: %v", err)
+	}
+	<-done
+	if got := recorded.list(); len(got) != 2 || got[0] != "request" || got[1] != "stop" {
+		t.Errorf("expected the request to drain before stop, got %v", got)
+	}
+}
+
+func TestServe_BoundedByShutdownTimeout(t *testing.T) {
+	h := handlers.NewHandlers(nil, nil, nil, nil, nil, nil, nil)
+	signals := make(chan os.Signal, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	stopping := make(chan struct{})
+	cfg := config.ServerConfig{ShutdownTimeout: 200 * time.Millisecond}
+
+	url, done := startServe(t, cfg, h, signals, started, release, func() { <-stopping })
+	defer close(stopping)
+
+	go func() {
+		if response, err := http.Get(url + "/stuck"); err == nil {
+			response.Body.Close()
+		}
+	}()
+	<-started
+
+	start := time.Now()
+	signals <- syscall.SIGTERM
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected serve to return at the shutdown timeout")
+	}
+	// Draining and stopping share the deadline
+	if elapsed := time.Since(start); elapsed < cfg.ShutdownTimeout || elapsed > cfg.ShutdownTimeout+time.Second {
+		t.Errorf("expected serve to return at the %s shutdown timeout, took %s", cfg.ShutdownTimeout, elapsed)
+	}
+}
+
+func serveReadiness(h *handlers.Handlers) int {
+	recorder := httptest.NewRecorder()
+	h.Readiness(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	return recorder.Code
+}
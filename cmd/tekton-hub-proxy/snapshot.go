@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/snapshot"
+	"tekton-hub-proxy/internal/translator"
+)
+
+// runSnapshotCommand implements the "snapshot" subcommand and returns the
+// process exit code.
+func runSnapshotCommand(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "Usage: tekton-hub-proxy snapshot export --output <file> [--config <file>]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("snapshot export", flag.ExitOnError)
+	var (
+		configPath = fs.String("config", "", "Path to config file")
+		output     = fs.String("output", "tekton-hub-snapshot.tar.gz", "Path of the snapshot archive to write")
+		debug      = fs.Bool("debug", false, "Enable debug logging")
+	)
+	_ = fs.Parse(args[1:])
+
+	cfg, err := config.LoadWithPath(*configPath)
+	if err != nil {
+		logrus.Errorf("Failed to load configuration: %v", err)
+		return 1
+	}
+	if *debug {
+		cfg.Logging.Level = "debug"
+	}
+	if err := validateConfig(cfg); err != nil {
+		logrus.Errorf("Invalid configuration: %v", err)
+		return 1
+	}
+	setupLogging(cfg.Logging)
+
+	// Every package is fetched exactly once, caching would only hold memory.
+	cfg.ArtifactHub.Cache.Enabled = false
+	artifactHubClient := client.NewArtifactHubClient(cfg.ArtifactHub)
+
+	kinds := translator.NewKindTable(cfg.Kinds)
+	categories := translator.NewCategoryTable(cfg.Categories)
+	versionTranslator, err := translator.NewVersionTranslatorWithPolicy(cfg.Versions.SimplifiedPolicy)
+	if err != nil {
+		logrus.Errorf("Invalid versions configuration: %v", err)
+		return 1
+	}
+	crawler := snapshot.NewCrawler(
+		artifactHubClient,
+		translator.NewCatalogTranslator(cfg.CatalogMappings, kinds),
+		translator.NewResponseTranslator(kinds, versionTranslator, categories),
+	)
+
+	store, err := crawler.Crawl(cfg.ArtifactHub.BaseURL, cfg.CatalogMappings)
+	if err != nil {
+		logrus.Errorf("Failed to crawl catalogs: %v", err)
+		return 1
+	}
+
+	if err := store.Save(*output); err != nil {
+		logrus.Errorf("Failed to write snapshot: %v", err)
+		return 1
+	}
+
+	meta := store.Metadata()
+	logrus.WithFields(logrus.Fields{
+		"output":   *output,
+		"packages": meta.Packages,
+		"versions": meta.Versions,
+		"errors":   meta.Errors,
+	}).Info("Snapshot written")
+
+	if meta.Errors > 0 {
+		logrus.Warnf("Snapshot is incomplete: %d packages or versions could not be fetched", meta.Errors)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/translator"
+)
+
+// validateConfig checks the configuration and what only the translators
+// and the policy engine can parse, and returns a config.ValidationError
+// listing every error found.
+func validateConfig(cfg *config.Config) error {
+	var errs []config.FieldError
+	if err := cfg.Validate(); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			return err
+		}
+		errs = append(errs, validationErr.Errors...)
+	}
+	// Sections with field errors aren't parsed again, which would report
+	// the same errors
+	failed := make(map[string]bool)
+	for _, fieldErr := range errs {
+		failed[section(fieldErr.Path)] = true
+	}
+	add := func(path string, err error) {
+		if err != nil && !failed[section(path)] {
+			errs = append(errs, config.FieldError{Path: path, Message: err.Error()})
+		}
+	}
+
+	versionTranslator, err := translator.NewVersionTranslatorWithPolicy(cfg.Versions.SimplifiedPolicy)
+	add("versions.simplified_policy", err)
+	if versionTranslator == nil {
+		versionTranslator = translator.NewVersionTranslator()
+	}
+	if cfg.Versions.PipelinesVersion != "" {
+		add("versions.pipelines_version", versionTranslator.ValidateVersion(cfg.Versions.PipelinesVersion))
+	}
+	add("catalog_mappings", translator.ValidateCatalogMappings(cfg.CatalogMappings, translator.NewKindTable(cfg.Kinds)))
+	_, err = translator.NewAliasTable(cfg.Aliases, versionTranslator)
+	add("aliases", err)
+	_, err = policy.NewEngine(cfg.Policy, versionTranslator)
+	add("policy", err)
+
+	if len(errs) > 0 {
+		return &config.ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// section returns the top level section of a field path.
+func section(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// runValidateConfigCommand implements the "validate-config" subcommand and
+// returns the process exit code.
+func runValidateConfigCommand(args []string) int {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to config file")
+	_ = fs.Parse(args)
+	if *configPath == "" && fs.NArg() == 1 {
+		*configPath = fs.Arg(0)
+	}
+
+	cfg, err := config.LoadWithPath(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		var validationErr *config.ValidationError
+		if !errors.As(err, &validationErr) {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "❌ Invalid configuration:")
+		for _, fieldErr := range validationErr.Errors {
+			fmt.Fprintf(os.Stderr, "  %s\n", fieldErr)
+		}
+		return 1
+	}
+
+	fmt.Println("✅ Configuration is valid")
+	return 0
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"time"
+)
+
+// ArtifactHub serves catalogs from Artifact Hub, or from anything answering
+// the same lookups such as a snapshot or a mirror.
+type ArtifactHub struct {
+	source client.PackageSource
+}
+
+var (
+	_ Backend           = (*ArtifactHub)(nil)
+	_ RepositoryBackend = (*ArtifactHub)(nil)
+)
+
+func NewArtifactHub(source client.PackageSource) *ArtifactHub {
+	return &ArtifactHub{source: source}
+}
+
+// Close closes the package source when it holds resources, such as the
+// cache of an Artifact Hub client.
+func (a *ArtifactHub) Close() error {
+	if closer, ok := a.source.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// SetCacheTTL changes the cache TTL of the package source when it caches
+// responses, such as an Artifact Hub client.
+func (a *ArtifactHub) SetCacheTTL(ttl time.Duration) {
+	if cached, ok := a.source.(interface{ SetCacheTTL(time.Duration) }); ok {
+		cached.SetCacheTTL(ttl)
+	}
+}
+
+func (a *ArtifactHub) GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	return a.source.GetPackageLatest(repoKind, catalog, name)
+}
+
+func (a *ArtifactHub) GetVersion(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	return a.source.GetPackage(repoKind, catalog, name, version)
+}
+
+func (a *ArtifactHub) ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error) {
+	pkg, err := a.source.GetPackageLatest(repoKind, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.AvailableVersions, nil
+}
+
+func (a *ArtifactHub) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	return a.source.SearchPackages(params)
+}
+
+// Repository returns the Artifact Hub repository of the catalog, when the
+// source knows about repositories.
+func (a *ArtifactHub) Repository(catalog string) (*models.ArtifactHubRepository, error) {
+	source, ok := a.source.(client.RepositorySource)
+	if !ok {
+		return nil, fmt.Errorf("source of catalog %q has no repository metadata", catalog)
+	}
+	return source.GetRepository(catalog)
+}
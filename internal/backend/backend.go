@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+)
+
+// Backend serves the resources of one or more catalogs. Results use the
+// Artifact Hub models, which the response translator turns into Tekton Hub
+// responses, so every backend looks like Artifact Hub to the handlers.
+type Backend interface {
+	GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error)
+	GetVersion(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error)
+	ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error)
+	Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error)
+}
+
+// RepositoryBackend is implemented by backends able to describe the
+// repository behind a catalog.
+type RepositoryBackend interface {
+	Repository(catalog string) (*models.ArtifactHubRepository, error)
+}
+
+// Registry selects the backend serving a catalog. Catalogs without a
+// dedicated backend are served by the default one.
+type Registry struct {
+	defaultBackend Backend
+	backends       map[string]Backend
+	order          []string
+}
+
+func NewRegistry(defaultBackend Backend) *Registry {
+	return &Registry{
+		defaultBackend: defaultBackend,
+		backends:       make(map[string]Backend),
+	}
+}
+
+// Register serves catalog from b.
+func (r *Registry) Register(catalog string, b Backend) {
+	if _, exists := r.backends[catalog]; !exists {
+		r.order = append(r.order, catalog)
+	}
+	r.backends[catalog] = b
+}
+
+// For returns the backend serving catalog.
+func (r *Registry) For(catalog string) Backend {
+	if b, ok := r.backends[catalog]; ok {
+		return b
+	}
+	return r.defaultBackend
+}
+
+// Search runs the search on every backend owning one of the requested
+// repositories and merges the results. Without repositories every backend
+// is searched.
+func (r *Registry) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	type group struct {
+		backend      Backend
+		repositories []string
+	}
+
+	var groups []*group
+	defaultGroup := &group{backend: r.defaultBackend}
+
+	if len(params.Repositories) == 0 {
+		groups = append(groups, defaultGroup)
+		for _, catalog := range r.order {
+			groups = append(groups, &group{backend: r.backends[catalog], repositories: []string{catalog}})
+		}
+	} else {
+		byCatalog := make(map[string]*group)
+		for _, repo := range params.Repositories {
+			b, ok := r.backends[repo]
+			if !ok {
+				if len(defaultGroup.repositories) == 0 {
+					groups = append(groups, defaultGroup)
+				}
+				defaultGroup.repositories = append(defaultGroup.repositories, repo)
+				continue
+			}
+			if g, exists := byCatalog[repo]; exists {
+				g.repositories = append(g.repositories, repo)
+				continue
+			}
+			g := &group{backend: b, repositories: []string{repo}}
+			byCatalog[repo] = g
+			groups = append(groups, g)
+		}
+	}
+
+	merged := &models.ArtifactHubSearchResponse{}
+	for _, g := range groups {
+		groupParams := params
+		groupParams.Repositories = g.repositories
+
+		result, err := g.backend.Search(groupParams)
+		if err != nil {
+			return nil, err
+		}
+		merged.Packages = append(merged.Packages, result.Packages...)
+		merged.Facets = append(merged.Facets, result.Facets...)
+	}
+
+	if params.Limit > 0 && len(merged.Packages) > params.Limit {
+		merged.Packages = merged.Packages[:params.Limit]
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,413 @@
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrNotFound is returned when a backend does not have the requested
+// resource or version. It is client.ErrNotFound, so callers tell missing
+// resources from failing backends the same way for every backend.
+var ErrNotFound = client.ErrNotFound
+
+// Filesystem serves a catalog checked out on disk using the tektoncd/catalog
+// layout: <root>/<kind>/<name>/<version>/<name>.yaml with an optional
+// README.md next to it. Lookups read the files on every request, searches
+// use an index rebuilt when a file or directory of the catalog changed.
+type Filesystem struct {
+	catalog            string
+	root               string
+	url                string
+	responseTranslator *translator.ResponseTranslator
+
+	indexMutex sync.Mutex
+	index      *searchIndex
+}
+
+// searchIndex holds the latest version of every resource of the catalog,
+// in directory order.
+type searchIndex struct {
+	stamp   treeStamp
+	entries []indexEntry
+}
+
+// indexEntry is a resource with the lowercased text searches match.
+type indexEntry struct {
+	repositoryKind int
+	text           string
+	summary        models.ArtifactHubPackageSummary
+}
+
+// treeStamp identifies the state of the catalog on disk: any file or
+// directory added, removed or written changes it.
+type treeStamp struct {
+	modTime time.Time
+	entries int
+}
+
+var _ Backend = (*Filesystem)(nil)
+
+func NewFilesystem(catalog, root string, responseTranslator *translator.ResponseTranslator) *Filesystem {
+	return &Filesystem{
+		catalog:            catalog,
+		root:               root,
+		url:                "file://" + root,
+		responseTranslator: responseTranslator,
+	}
+}
+
+// manifest holds the parts of a Tekton resource the proxy reports on.
+type manifest struct {
+	Metadata struct {
+		Name        string            `yaml:"name"`
+		Labels      map[string]string `yaml:"labels"`
+		Annotations map[string]string `yaml:"annotations"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Description string `yaml:"description"`
+	} `yaml:"spec"`
+}
+
+// resourceVersion is one version directory of a resource.
+type resourceVersion struct {
+	version *version.Version
+	dir     string
+}
+
+func (f *Filesystem) GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	kind, versions, err := f.versions(repoKind, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.loadPackage(kind, name, versions[len(versions)-1], versions)
+}
+
+func (f *Filesystem) GetVersion(repoKind, catalog, name, ver string) (*models.ArtifactHubPackage, error) {
+	kind, versions, err := f.versions(repoKind, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+
+	requested, err := version.NewVersion(ver)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s version %s: %w", kind, name, ver, ErrNotFound)
+	}
+	for _, v := range versions {
+		if v.version.Equal(requested) {
+			return f.loadPackage(kind, name, v, versions)
+		}
+	}
+	return nil, fmt.Errorf("%s/%s version %s: %w", kind, name, ver, ErrNotFound)
+}
+
+func (f *Filesystem) ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error) {
+	kind, versions, err := f.versions(repoKind, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	return f.availableVersions(kind, name, versions), nil
+}
+
+// Search matches every query term against the name, display name,
+// description and tags of the latest version of the resources.
+func (f *Filesystem) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	if len(params.Repositories) > 0 && !slices.Contains(params.Repositories, f.catalog) {
+		return &models.ArtifactHubSearchResponse{}, nil
+	}
+
+	index, err := f.searchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	terms := strings.Fields(strings.ToLower(params.Query))
+	var summaries []models.ArtifactHubPackageSummary
+	for _, entry := range index.entries {
+		if len(params.Kinds) > 0 && !slices.Contains(params.Kinds, entry.repositoryKind) {
+			continue
+		}
+		if !matchesTerms(terms, entry.text) {
+			continue
+		}
+		summaries = append(summaries, entry.summary)
+	}
+
+	if params.Offset > 0 {
+		if params.Offset >= len(summaries) {
+			summaries = nil
+		} else {
+			summaries = summaries[params.Offset:]
+		}
+	}
+	if params.Limit > 0 && len(summaries) > params.Limit {
+		summaries = summaries[:params.Limit]
+	}
+
+	return &models.ArtifactHubSearchResponse{Packages: summaries}, nil
+}
+
+// searchIndex returns the index of the catalog, rebuilt when the catalog
+// changed on disk since it was built. Git catalogs serve every refresh from
+// a new Filesystem, and so a new index.
+func (f *Filesystem) searchIndex() (*searchIndex, error) {
+	stamp, err := f.stamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", f.catalog, err)
+	}
+
+	f.indexMutex.Lock()
+	defer f.indexMutex.Unlock()
+	if f.index != nil && f.index.stamp == stamp {
+		return f.index, nil
+	}
+
+	index, err := f.buildIndex(stamp)
+	if err != nil {
+		return nil, err
+	}
+	f.index = index
+	return index, nil
+}
+
+// stamp walks the catalog for the latest modification time and the number
+// of entries, which also catches removals.
+func (f *Filesystem) stamp() (treeStamp, error) {
+	var stamp treeStamp
+	err := filepath.WalkDir(f.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		stamp.entries++
+		if info.ModTime().After(stamp.modTime) {
+			stamp.modTime = info.ModTime()
+		}
+		return nil
+	})
+	return stamp, err
+}
+
+// buildIndex loads the latest version of every resource of the catalog.
+func (f *Filesystem) buildIndex(stamp treeStamp) (*searchIndex, error) {
+	kindDirs, err := os.ReadDir(f.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", f.catalog, err)
+	}
+
+	index := &searchIndex{stamp: stamp}
+	for _, kindDir := range kindDirs {
+		if !kindDir.IsDir() {
+			continue
+		}
+		kind := kindDir.Name()
+		repositoryKind, err := f.responseTranslator.Kinds().RepositoryKind(kind)
+		if err != nil {
+			continue
+		}
+
+		nameDirs, err := os.ReadDir(filepath.Join(f.root, kind))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog %s: %w", f.catalog, err)
+		}
+		for _, nameDir := range nameDirs {
+			if !nameDir.IsDir() {
+				continue
+			}
+			repoKind, _ := f.responseTranslator.Kinds().RepoKind(kind)
+			pkg, err := f.GetLatest(repoKind, f.catalog, nameDir.Name())
+			if err != nil {
+				continue
+			}
+			index.entries = append(index.entries, indexEntry{
+				repositoryKind: repositoryKind,
+				text:           strings.ToLower(strings.Join([]string{pkg.Name, pkg.DisplayName, pkg.Description, strings.Join(pkg.Keywords, " ")}, " ")),
+				summary:        packageSummary(pkg),
+			})
+		}
+	}
+	return index, nil
+}
+
+// versions returns the kind directory and the version directories of a
+// resource, oldest first.
+func (f *Filesystem) versions(repoKind, catalog, name string) (string, []resourceVersion, error) {
+	kind, err := f.responseTranslator.Kinds().FromRepoKind(repoKind)
+	if err != nil || catalog != f.catalog || !validSegment(kind) || !validSegment(name) {
+		return "", nil, fmt.Errorf("%s/%s/%s: %w", catalog, repoKind, name, ErrNotFound)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(f.root, kind, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("%s/%s/%s: %w", catalog, kind, name, ErrNotFound)
+		}
+		return "", nil, fmt.Errorf("failed to read %s/%s: %w", kind, name, err)
+	}
+
+	var versions []resourceVersion
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := version.NewVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(f.root, kind, name, entry.Name(), name+".yaml")); err != nil {
+			continue
+		}
+		versions = append(versions, resourceVersion{version: v, dir: entry.Name()})
+	}
+
+	if len(versions) == 0 {
+		return "", nil, fmt.Errorf("%s/%s/%s: %w", catalog, kind, name, ErrNotFound)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].version.LessThan(versions[j].version)
+	})
+	return kind, versions, nil
+}
+
+func (f *Filesystem) availableVersions(kind, name string, versions []resourceVersion) []models.ArtifactHubVersion {
+	available := make([]models.ArtifactHubVersion, 0, len(versions))
+	for _, v := range versions {
+		var ts int64
+		if info, err := os.Stat(filepath.Join(f.root, kind, name, v.dir, name+".yaml")); err == nil {
+			ts = info.ModTime().Unix()
+		}
+		available = append(available, models.ArtifactHubVersion{
+			Version:    v.version.String(),
+			Prerelease: v.version.Prerelease() != "",
+			TS:         ts,
+		})
+	}
+	return available
+}
+
+func (f *Filesystem) loadPackage(kind, name string, v resourceVersion, all []resourceVersion) (*models.ArtifactHubPackage, error) {
+	dir := filepath.Join(f.root, kind, name, v.dir)
+	manifestPath := filepath.Join(dir, name+".yaml")
+
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", manifestPath, err)
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", manifestPath, err)
+	}
+
+	repositoryKind, err := f.responseTranslator.Kinds().RepositoryKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read README of %s/%s: %w", kind, name, err)
+	}
+
+	var ts int64
+	if info, err := os.Stat(manifestPath); err == nil {
+		ts = info.ModTime().Unix()
+	}
+
+	displayName := m.Metadata.Annotations["tekton.dev/displayName"]
+	if displayName == "" {
+		displayName = name
+	}
+
+	available := f.availableVersions(kind, name, all)
+	latest := all[len(all)-1].version.String()
+
+	return &models.ArtifactHubPackage{
+		PackageID:         fmt.Sprintf("%s/%s/%s", f.catalog, kind, name),
+		Name:              name,
+		NormalizedName:    name,
+		DisplayName:       displayName,
+		Description:       firstLine(m.Spec.Description),
+		Version:           v.version.String(),
+		Deprecated:        m.Metadata.Annotations["tekton.dev/deprecated"] == "true",
+		Prerelease:        v.version.Prerelease() != "",
+		TS:                ts,
+		LatestVersion:     latest,
+		AvailableVersions: available,
+		Keywords:          splitList(m.Metadata.Annotations["tekton.dev/tags"]),
+		README:            string(readme),
+		Repository: models.ArtifactHubRepository{
+			RepositoryID: f.catalog,
+			Kind:         repositoryKind,
+			Name:         f.catalog,
+			DisplayName:  f.catalog,
+			URL:          f.url,
+		},
+		Data: models.ArtifactHubPackageData{
+			ManifestRaw:         string(raw),
+			PipelinesMinVersion: m.Metadata.Annotations["tekton.dev/pipelines.minVersion"],
+		},
+	}, nil
+}
+
+func packageSummary(pkg *models.ArtifactHubPackage) models.ArtifactHubPackageSummary {
+	return models.ArtifactHubPackageSummary{
+		PackageID:      pkg.PackageID,
+		Name:           pkg.Name,
+		NormalizedName: pkg.NormalizedName,
+		DisplayName:    pkg.DisplayName,
+		Description:    pkg.Description,
+		Version:        pkg.Version,
+		Deprecated:     pkg.Deprecated,
+		TS:             pkg.TS,
+		Repository:     pkg.Repository,
+	}
+}
+
+func validSegment(segment string) bool {
+	return segment != "" && segment != "." && segment != ".." && !strings.ContainsAny(segment, `/\`)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matchesTerms reports whether the lowercased text contains every term.
+func matchesTerms(terms []string, text string) bool {
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
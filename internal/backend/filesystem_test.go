@@ -0,0 +1,200 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+)
+
+const gitCloneTask = `apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: git-clone
+  annotations:
+    tekton.dev/pipelines.minVersion: "0.38.0"
+    tekton.dev/displayName: "Git Clone"
+    tekton.dev/tags: git, scm
+spec:
+  description: >-
+    Clone a git repository.
+`
+
+func writeCatalogFile(t *testing.T, root string, elem ...string) {
+	t.Helper()
+	path := filepath.Join(append([]string{root}, elem...)...)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := gitCloneTask
+	if filepath.Base(path) == "README.md" {
+		content = "# git-clone " + elem[len(elem)-2]
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestFilesystem(t *testing.T) *Filesystem {
+	root := t.TempDir()
+	writeCatalogFile(t, root, "task", "git-clone", "0.1", "git-clone.yaml")
+	writeCatalogFile(t, root, "task", "git-clone", "0.1", "README.md")
+	writeCatalogFile(t, root, "task", "git-clone", "0.10", "git-clone.yaml")
+	writeCatalogFile(t, root, "task", "git-clone", "0.9", "git-clone.yaml")
+	writeCatalogFile(t, root, "task", "git-clone", "notes", "git-clone.yaml")
+	return NewFilesystem("internal", root, translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+}
+
+func TestFilesystemGetLatest(t *testing.T) {
+	fs := newTestFilesystem(t)
+
+	pkg, err := fs.GetLatest("tekton-task", "internal", "git-clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pkg.Version != "0.10.0" {
+		t.Errorf("expected latest 0.10.0, got %s", pkg.Version)
+	}
+	if pkg.DisplayName != "Git Clone" || pkg.Description != "Clone a git repository." {
+		t.Errorf("unexpected metadata: %q %q", pkg.DisplayName, pkg.Description)
+	}
+	if pkg.Data.PipelinesMinVersion != "0.38.0" {
+		t.Errorf("expected min pipelines version 0.38.0, got %q", pkg.Data.PipelinesMinVersion)
+	}
+	if len(pkg.Keywords) != 2 || pkg.Keywords[0] != "git" || pkg.Keywords[1] != "scm" {
+		t.Errorf("unexpected keywords: %v", pkg.Keywords)
+	}
+	if len(pkg.AvailableVersions) != 3 {
+		t.Errorf("expected 3 versions, got %v", pkg.AvailableVersions)
+	}
+}
+
+func TestFilesystemGetVersion(t *testing.T) {
+	fs := newTestFilesystem(t)
+
+	pkg, err := fs.GetVersion("tekton-task", "internal", "git-clone", "0.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pkg.README != "# git-clone 0.1" {
+		t.Errorf("unexpected README %q", pkg.README)
+	}
+	if pkg.Data.ManifestRaw != gitCloneTask {
+		t.Errorf("unexpected manifest %q", pkg.Data.ManifestRaw)
+	}
+
+	tests := []struct {
+		name     string
+		repoKind string
+		catalog  string
+		resource string
+		version  string
+	}{
+		{name: "unknown version", repoKind: "tekton-task", catalog: "internal", resource: "git-clone", version: "0.2.0"},
+		{name: "unknown resource", repoKind: "tekton-task", catalog: "internal", resource: "buildah", version: "0.1.0"},
+		{name: "other catalog", repoKind: "tekton-task", catalog: "tekton", resource: "git-clone", version: "0.1.0"},
+		{name: "path traversal", repoKind: "tekton-..", catalog: "internal", resource: "..", version: "0.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fs.GetVersion(tt.repoKind, tt.catalog, tt.resource, tt.version); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRegistrySearch(t *testing.T) {
+	registry := NewRegistry(&staticBackend{summary: models.ArtifactHubPackageSummary{Name: "buildah"}})
+	registry.Register("internal", newTestFilesystem(t))
+
+	tests := []struct {
+		name     string
+		params   client.SearchParams
+		expected []string
+	}{
+		{name: "all backends", params: client.SearchParams{}, expected: []string{"buildah", "git-clone"}},
+		{name: "filesystem only", params: client.SearchParams{Repositories: []string{"internal"}}, expected: []string{"git-clone"}},
+		{name: "default only", params: client.SearchParams{Repositories: []string{"tekton-catalog-tasks"}}, expected: []string{"buildah"}},
+		{name: "query", params: client.SearchParams{Repositories: []string{"internal"}, Query: "scm"}, expected: []string{"git-clone"}},
+		{name: "limit", params: client.SearchParams{Limit: 1}, expected: []string{"buildah"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := registry.Search(tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, pkg := range result.Packages {
+				names = append(names, pkg.Name)
+			}
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, names)
+				}
+			}
+		})
+	}
+}
+
+type staticBackend struct {
+	summary models.ArtifactHubPackageSummary
+}
+
+func (s *staticBackend) GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	return nil, ErrNotFound
+}
+
+func (s *staticBackend) GetVersion(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	return nil, ErrNotFound
+}
+
+func (s *staticBackend) ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error) {
+	return nil, ErrNotFound
+}
+
+func (s *staticBackend) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	return &models.ArtifactHubSearchResponse{Packages: []models.ArtifactHubPackageSummary{s.summary}}, nil
+}
+
+func TestFilesystemSearchIndex(t *testing.T) {
+	f := newTestFilesystem(t)
+
+	search := func() []string {
+		t.Helper()
+		result, err := f.Search(client.SearchParams{Query: "clone"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, pkg := range result.Packages {
+			names = append(names, pkg.Name)
+		}
+		return names
+	}
+
+	if names := search(); len(names) != 1 {
+		t.Fatalf("expected git-clone, got %v", names)
+	}
+	index := f.index
+	search()
+	if f.index != index {
+		t.Error("expected the index to be reused while the catalog is unchanged")
+	}
+
+	// Resources added on disk are found by the next search
+	writeCatalogFile(t, f.root, "task", "git-clone-lfs", "0.1", "git-clone-lfs.yaml")
+	if names := search(); len(names) != 2 {
+		t.Errorf("expected the added resource to be indexed, got %v", names)
+	}
+}
@@ -0,0 +1,397 @@
+package backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// How versions of a git catalog are discovered.
+const (
+	// GitVersionsLayout reads versions from the <kind>/<name>/<version>/
+	// directories of the configured ref.
+	GitVersionsLayout = "layout"
+	// GitVersionsTags reads versions from tags: "v1.2.0" versions every
+	// resource of the tagged tree, "git-clone-v1.2.0" only git-clone. The
+	// tagged tree holds <kind>/<name>/<name>.yaml without version directory.
+	GitVersionsTags = "tags"
+)
+
+// defaultGitTimeout bounds git commands when the catalog sets no timeout.
+const defaultGitTimeout = 5 * time.Minute
+
+var versionTagRegex = regexp.MustCompile(`^(?:(.+)-)?v?(\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?)$`)
+
+// GitOptions configures a git catalog.
+type GitOptions struct {
+	URL             string
+	Ref             string
+	Versions        string
+	RefreshInterval time.Duration
+	// Timeout bounds each git command, a hanging remote can't block
+	// refreshes forever.
+	Timeout time.Duration
+	// Dir holds the clone and the materialized catalog trees.
+	Dir string
+}
+
+// Git serves a git repository following the tektoncd/catalog layout. The
+// repository is cloned once and fetched again when older than the refresh
+// interval. Every fetch materializes the catalog into a fresh directory,
+// served by a Filesystem backend and swapped in atomically.
+type Git struct {
+	catalog            string
+	opts               GitOptions
+	responseTranslator *translator.ResponseTranslator
+
+	current    atomic.Pointer[Filesystem]
+	refreshing atomic.Bool
+	refreshes  sync.WaitGroup
+
+	mutex       sync.Mutex
+	lastRefresh time.Time
+	trees       []string
+	closed      bool
+}
+
+var (
+	_ Backend           = (*Git)(nil)
+	_ RepositoryBackend = (*Git)(nil)
+	_ io.Closer         = (*Git)(nil)
+)
+
+// ErrGitClosed is returned by refreshes of a closed git catalog.
+var ErrGitClosed = errors.New("git catalog closed")
+
+// NewGit clones the repository and materializes the catalog.
+func NewGit(catalog string, opts GitOptions, responseTranslator *translator.ResponseTranslator) (*Git, error) {
+	if opts.Ref == "" {
+		opts.Ref = "HEAD"
+	}
+	if opts.Versions == "" {
+		opts.Versions = GitVersionsLayout
+	}
+	if opts.Versions != GitVersionsLayout && opts.Versions != GitVersionsTags {
+		return nil, fmt.Errorf("unknown git versions mode %q", opts.Versions)
+	}
+	if opts.Dir == "" {
+		opts.Dir = filepath.Join(os.TempDir(), "tekton-hub-proxy-git", catalog)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultGitTimeout
+	}
+
+	g := &Git{
+		catalog:            catalog,
+		opts:               opts,
+		responseTranslator: responseTranslator,
+	}
+
+	if err := g.clone(); err != nil {
+		return nil, err
+	}
+	if err := g.Refresh(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *Git) repoDir() string {
+	return filepath.Join(g.opts.Dir, "repo.git")
+}
+
+func (g *Git) clone() error {
+	// Trees materialized by a previous run are never served again.
+	stale, _ := filepath.Glob(filepath.Join(g.opts.Dir, "tree-*"))
+	for _, tree := range stale {
+		_ = os.RemoveAll(tree)
+	}
+
+	if _, err := os.Stat(g.repoDir()); err == nil {
+		// Clones of another repository left in the directory are replaced
+		origin, err := g.runGit(g.repoDir(), "config", "--get", "remote.origin.url")
+		if err == nil && strings.TrimSpace(origin) == g.opts.URL {
+			return nil
+		}
+		logrus.WithFields(logrus.Fields{
+			"catalog": g.catalog,
+			"url":     g.opts.URL,
+			"origin":  strings.TrimSpace(origin),
+		}).Warn("Git directory holds another repository, cloning again")
+		if err := os.RemoveAll(g.repoDir()); err != nil {
+			return fmt.Errorf("failed to remove previous clone: %w", err)
+		}
+	}
+	if err := os.MkdirAll(g.opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create git directory: %w", err)
+	}
+	if _, err := g.runGit("", "clone", "--bare", "--quiet", g.opts.URL, g.repoDir()); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", g.opts.URL, err)
+	}
+	return nil
+}
+
+// Refresh fetches the repository and swaps in the new catalog tree.
+func (g *Git) Refresh() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.closed {
+		return ErrGitClosed
+	}
+	if _, err := g.runGit(g.repoDir(), "fetch", "--quiet", "--prune", "--tags", "--force", "origin", "+refs/heads/*:refs/heads/*"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", g.opts.URL, err)
+	}
+
+	tree, err := os.MkdirTemp(g.opts.Dir, "tree-")
+	if err != nil {
+		return fmt.Errorf("failed to create catalog tree: %w", err)
+	}
+
+	if g.opts.Versions == GitVersionsTags {
+		err = g.materializeTags(tree)
+	} else {
+		err = g.extractRevision(g.opts.Ref, func(name string) (string, bool) { return name, true }, tree)
+	}
+	if err != nil {
+		_ = os.RemoveAll(tree)
+		return err
+	}
+
+	fs := NewFilesystem(g.catalog, tree, g.responseTranslator)
+	fs.url = g.opts.URL
+	g.current.Store(fs)
+	g.lastRefresh = time.Now()
+
+	// Keep the previous tree around for requests still reading from it.
+	g.trees = append(g.trees, tree)
+	for len(g.trees) > 2 {
+		_ = os.RemoveAll(g.trees[0])
+		g.trees = g.trees[1:]
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"catalog":  g.catalog,
+		"url":      g.opts.URL,
+		"ref":      g.opts.Ref,
+		"versions": g.opts.Versions,
+	}).Info("Git catalog refreshed")
+	return nil
+}
+
+// materializeTags lays out every version tag as a version directory.
+func (g *Git) materializeTags(tree string) error {
+	out, err := g.runGit(g.repoDir(), "tag", "--list")
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	for _, tag := range strings.Fields(out) {
+		matches := versionTagRegex.FindStringSubmatch(tag)
+		if matches == nil {
+			continue
+		}
+		resource, version := matches[1], matches[2]
+
+		// <kind>/<name>/<file> becomes <kind>/<name>/<version>/<file>
+		rename := func(name string) (string, bool) {
+			parts := strings.Split(name, "/")
+			if len(parts) != 3 || (resource != "" && parts[1] != resource) {
+				return "", false
+			}
+			if parts[2] != parts[1]+".yaml" && parts[2] != "README.md" {
+				return "", false
+			}
+			return path.Join(parts[0], parts[1], version, parts[2]), true
+		}
+		if err := g.extractRevision(tag, rename, tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maybeRefresh starts a background refresh when the tree is stale. Requests
+// never wait for git.
+func (g *Git) maybeRefresh() {
+	if g.opts.RefreshInterval <= 0 {
+		return
+	}
+
+	g.mutex.Lock()
+	stale := !g.closed && time.Since(g.lastRefresh) > g.opts.RefreshInterval
+	if !stale || !g.refreshing.CompareAndSwap(false, true) {
+		g.mutex.Unlock()
+		return
+	}
+	// Added under the mutex, so Close waits for every refresh started
+	g.refreshes.Add(1)
+	g.mutex.Unlock()
+
+	go func() {
+		defer g.refreshes.Done()
+		defer g.refreshing.Store(false)
+		if err := g.Refresh(); err != nil && !errors.Is(err, ErrGitClosed) {
+			logrus.WithError(err).WithField("catalog", g.catalog).Error("Failed to refresh git catalog, serving previous tree")
+		}
+	}()
+}
+
+// Close waits for a refresh in progress and removes the catalog trees the
+// backend materialized. The clone is kept, so the next start only fetches.
+func (g *Git) Close() error {
+	g.mutex.Lock()
+	g.closed = true
+	g.mutex.Unlock()
+	g.refreshes.Wait()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	var errs []error
+	for _, tree := range g.trees {
+		if err := os.RemoveAll(tree); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	g.trees = nil
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to remove catalog trees of %s: %w", g.catalog, err)
+	}
+	return nil
+}
+
+func (g *Git) GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	g.maybeRefresh()
+	return g.current.Load().GetLatest(repoKind, catalog, name)
+}
+
+func (g *Git) GetVersion(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	g.maybeRefresh()
+	return g.current.Load().GetVersion(repoKind, catalog, name, version)
+}
+
+func (g *Git) ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error) {
+	g.maybeRefresh()
+	return g.current.Load().ListVersions(repoKind, catalog, name)
+}
+
+func (g *Git) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	g.maybeRefresh()
+	return g.current.Load().Search(params)
+}
+
+// Repository describes the cloned repository.
+func (g *Git) Repository(catalog string) (*models.ArtifactHubRepository, error) {
+	return &models.ArtifactHubRepository{
+		Name:        catalog,
+		DisplayName: catalog,
+		URL:         g.opts.URL,
+	}, nil
+}
+
+// extractRevision writes the files of a revision into dir. rename maps the
+// path of each file in the repository to its path below dir, or skips it.
+func (g *Git) extractRevision(revision string, rename func(string) (string, bool), dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.opts.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "--git-dir", g.repoDir(), "archive", "--format=tar", revision)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run git archive: %w", err)
+	}
+
+	extractErr := extractTar(stdout, rename, dir)
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive %s: %w: %s", revision, err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+func extractTar(r io.Reader, rename func(string) (string, bool), dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read git archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name, ok := rename(path.Clean(hdr.Name))
+		if !ok {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %q in git archive", hdr.Name)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			_ = f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		if err := os.Chtimes(target, hdr.ModTime, hdr.ModTime); err != nil {
+			return err
+		}
+	}
+}
+
+// runGit runs a git command within the timeout of the catalog.
+func (g *Git) runGit(gitDir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.opts.Timeout)
+	defer cancel()
+
+	subcommand := args[0]
+	if gitDir != "" {
+		args = append([]string{"--git-dir", gitDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return "", fmt.Errorf("git %s: %w: %s", subcommand, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
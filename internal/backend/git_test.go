@@ -0,0 +1,200 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+	"time"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-q", "-m", "update "+name)
+}
+
+func TestGitLayoutVersions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	upstream := t.TempDir()
+	git(t, upstream, "init", "-q")
+	commitFile(t, upstream, "task/git-clone/0.1/git-clone.yaml", gitCloneTask)
+	commitFile(t, upstream, "task/git-clone/0.1/README.md", "# 0.1")
+
+	g, err := NewGit("internal", GitOptions{URL: upstream, Dir: t.TempDir()}, translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	if err != nil {
+		t.Fatalf("failed to create git backend: %v", err)
+	}
+
+	pkg, err := g.GetLatest("tekton-task", "internal", "git-clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pkg.Version != "0.1.0" || pkg.README != "# 0.1" || pkg.Repository.URL != upstream {
+		t.Errorf("unexpected package: version %q readme %q url %q", pkg.Version, pkg.README, pkg.Repository.URL)
+	}
+
+	commitFile(t, upstream, "task/git-clone/0.2/git-clone.yaml", gitCloneTask)
+	if err := g.Refresh(); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+
+	versions, err := g.ListVersions("tekton-task", "internal", "git-clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(versions) != 2 || versions[1].Version != "0.2.0" {
+		t.Errorf("expected versions 0.1.0 and 0.2.0 after refresh, got %v", versions)
+	}
+}
+
+func TestGitTagVersions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	upstream := t.TempDir()
+	git(t, upstream, "init", "-q")
+	commitFile(t, upstream, "task/git-clone/git-clone.yaml", gitCloneTask+"# v0.1.0\n")
+	commitFile(t, upstream, "task/buildah/buildah.yaml", gitCloneTask)
+	git(t, upstream, "tag", "v0.1.0")
+	commitFile(t, upstream, "task/git-clone/git-clone.yaml", gitCloneTask+"# v0.2.0\n")
+	git(t, upstream, "tag", "git-clone-v0.2.0")
+	git(t, upstream, "tag", "not-a-version")
+
+	g, err := NewGit("internal", GitOptions{URL: upstream, Versions: GitVersionsTags, Dir: t.TempDir()}, translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	if err != nil {
+		t.Fatalf("failed to create git backend: %v", err)
+	}
+
+	old, err := g.GetVersion("tekton-task", "internal", "git-clone", "0.1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if old.Data.ManifestRaw != gitCloneTask+"# v0.1.0\n" {
+		t.Errorf("unexpected manifest for 0.1.0: %q", old.Data.ManifestRaw)
+	}
+
+	latest, err := g.GetLatest("tekton-task", "internal", "git-clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest.Version != "0.2.0" {
+		t.Errorf("expected latest 0.2.0, got %s", latest.Version)
+	}
+
+	// The resource specific tag does not version other resources.
+	buildah, err := g.ListVersions("tekton-task", "internal", "buildah")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buildah) != 1 || buildah[0].Version != "0.1.0" {
+		t.Errorf("expected buildah to only have 0.1.0, got %v", buildah)
+	}
+}
+
+func TestGitClonesAgainForAnotherURL(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	for _, upstream := range []string{first, second} {
+		git(t, upstream, "init", "-q")
+	}
+	commitFile(t, first, "task/git-clone/0.1/git-clone.yaml", gitCloneTask)
+	commitFile(t, second, "task/git-clone/0.2/git-clone.yaml", gitCloneTask)
+
+	dir := t.TempDir()
+	responseTranslator := translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable())
+	if _, err := NewGit("internal", GitOptions{URL: first, Dir: dir}, responseTranslator); err != nil {
+		t.Fatalf("failed to create git backend: %v", err)
+	}
+	g, err := NewGit("internal", GitOptions{URL: second, Dir: dir}, responseTranslator)
+	if err != nil {
+		t.Fatalf("failed to create git backend: %v", err)
+	}
+
+	pkg, err := g.GetLatest("tekton-task", "internal", "git-clone")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pkg.Version != "0.2.0" {
+		t.Errorf("expected the resource of the configured repository, got version %s", pkg.Version)
+	}
+}
+
+func TestGitTimeout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	upstream := t.TempDir()
+	git(t, upstream, "init", "-q")
+	commitFile(t, upstream, "task/git-clone/0.1/git-clone.yaml", gitCloneTask)
+
+	_, err := NewGit("internal", GitOptions{URL: upstream, Timeout: time.Nanosecond, Dir: t.TempDir()}, translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the clone to time out, got %v", err)
+	}
+}
+
+func TestGitClose(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	upstream := t.TempDir()
+	git(t, upstream, "init", "-q")
+	commitFile(t, upstream, "task/git-clone/0.1/git-clone.yaml", gitCloneTask)
+
+	dir := t.TempDir()
+	g, err := NewGit("internal", GitOptions{URL: upstream, RefreshInterval: time.Nanosecond, Dir: dir}, translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	if err != nil {
+		t.Fatalf("failed to create git backend: %v", err)
+	}
+
+	// Starts a background refresh, Close waits for it
+	commitFile(t, upstream, "task/git-clone/0.2/git-clone.yaml", gitCloneTask)
+	if _, err := g.GetLatest("tekton-task", "internal", "git-clone"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if g.refreshing.Load() {
+		t.Error("expected the refresh to be finished")
+	}
+	if trees, _ := filepath.Glob(filepath.Join(dir, "tree-*")); len(trees) != 0 {
+		t.Errorf("expected the catalog trees to be removed, got %v", trees)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "repo.git")); err != nil {
+		t.Errorf("expected the clone to be kept: %v", err)
+	}
+	if err := g.Refresh(); !errors.Is(err, ErrGitClosed) {
+		t.Errorf("expected refreshes of a closed catalog to fail, got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/metrics"
+)
+
+var reloads = metrics.NewCounterVec(
+	"tekton_hub_proxy_tls_reloads_total",
+	"TLS certificate reloads by result.",
+	"result",
+)
+
+// reloadDelay lets rotations writing several files settle before they are
+// read, like the atomic symlink swap of Kubernetes secret volumes.
+const reloadDelay = 500 * time.Millisecond
+
+var minVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Reloader serves the certificate and client CAs of a TLS configuration and
+// reloads them when their files change. Handshakes use the files loaded
+// last, established connections keep theirs.
+type Reloader struct {
+	cfg        config.TLSConfig
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+
+	current atomic.Pointer[material]
+	watcher *fsnotify.Watcher
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// material is the content of the files loaded at once.
+type material struct {
+	files       [][]byte
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	notAfter    time.Time
+	loadedAt    time.Time
+}
+
+// Status is the certificate served.
+type Status struct {
+	NotAfter time.Time `json:"not_after"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Expired  bool      `json:"expired"`
+}
+
+// NewReloader loads the files of the configuration.
+func NewReloader(cfg config.TLSConfig) (*Reloader, error) {
+	minVersion, ok := minVersions[cfg.MinVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported TLS version %q", cfg.MinVersion)
+	}
+	clientAuth := tls.NoClientCert
+	if cfg.ClientCAFile != "" {
+		clientAuth = tls.RequireAndVerifyClientCert
+		if cfg.ClientAuth == config.ClientAuthVerifyIfGiven {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	r := &Reloader{
+		cfg:        cfg,
+		minVersion: minVersion,
+		clientAuth: clientAuth,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	m, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(m)
+	return r, nil
+}
+
+// TLSConfig returns the server configuration using the current files.
+func (r *Reloader) TLSConfig() *tls.Config {
+	getCertificate := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.current.Load().certificate, nil
+	}
+	return &tls.Config{
+		MinVersion:     r.minVersion,
+		GetCertificate: getCertificate,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:     r.minVersion,
+				NextProtos:     []string{"h2", "http/1.1"},
+				GetCertificate: getCertificate,
+				ClientAuth:     r.clientAuth,
+				ClientCAs:      r.current.Load().clientCAs,
+			}, nil
+		},
+	}
+}
+
+// load reads and parses the files.
+func (r *Reloader) load() (*material, error) {
+	paths := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		paths = append(paths, r.cfg.ClientCAFile)
+	}
+	m := &material{loadedAt: time.Now().UTC()}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		m.files = append(m.files, data)
+	}
+
+	certificate, err := tls.X509KeyPair(m.files[0], m.files[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate %s: %w", r.cfg.CertFile, err)
+	}
+	m.certificate = &certificate
+	m.notAfter = certificate.Leaf.NotAfter
+
+	if r.cfg.ClientCAFile != "" {
+		m.clientCAs = x509.NewCertPool()
+		if !m.clientCAs.AppendCertsFromPEM(m.files[2]) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", r.cfg.ClientCAFile)
+		}
+	}
+	return m, nil
+}
+
+// Start watches the directories of the files, which also sees files
+// replaced by renames or symlink swaps.
+func (r *Reloader) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch certificates: %w", err)
+	}
+	dirs := make(map[string]bool)
+	for _, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if path == "" || dirs[filepath.Dir(path)] {
+			continue
+		}
+		dirs[filepath.Dir(path)] = true
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+		}
+	}
+	r.watcher = watcher
+
+	go r.loop()
+	return nil
+}
+
+// Stop ends watching the files.
+func (r *Reloader) Stop() {
+	close(r.stop)
+	if r.watcher != nil {
+		<-r.done
+	}
+}
+
+func (r *Reloader) loop() {
+	defer close(r.done)
+	defer r.watcher.Close() //nolint:errcheck
+
+	var timer *time.Timer
+	for {
+		select {
+		case <-r.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case err := <-r.watcher.Errors:
+			logrus.WithError(err).Warn("Error watching certificates")
+		case <-r.watcher.Events:
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(reloadDelay, r.Reload)
+		}
+	}
+}
+
+// Reload reads the files again and swaps them in when they changed. Files
+// that don't load keep the current certificate.
+func (r *Reloader) Reload() {
+	m, err := r.load()
+	if err != nil {
+		reloads.Inc("failed")
+		logrus.WithError(err).Error("❌ Certificate reload failed, serving the current certificate")
+		return
+	}
+
+	previous := r.current.Load()
+	if len(previous.files) == len(m.files) {
+		unchanged := true
+		for i := range m.files {
+			unchanged = unchanged && bytes.Equal(previous.files[i], m.files[i])
+		}
+		if unchanged {
+			return
+		}
+	}
+
+	r.current.Store(m)
+	reloads.Inc("success")
+	logrus.WithFields(logrus.Fields{
+		"cert_file": r.cfg.CertFile,
+		"not_after": m.notAfter,
+	}).Info("🔐 Certificate reloaded")
+}
+
+// HealthStatus reports the certificate served. An expired certificate
+// doesn't fail health checks, restarts wouldn't renew it.
+func (r *Reloader) HealthStatus() (any, bool) {
+	m := r.current.Load()
+	return Status{NotAfter: m.notAfter, LoadedAt: m.loadedAt, Expired: time.Now().After(m.notAfter)}, true
+}
@@ -0,0 +1,144 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tekton-hub-proxy/internal/config"
+)
+
+// writeCertificate writes a self-signed certificate for name and its key.
+func writeCertificate(t *testing.T, dir, name string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, "tls.crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tls.key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servedName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	serverConfig, err := r.TLSConfig().GetConfigForClient(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := serverConfig.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate.Leaf.Subject.CommonName
+}
+
+func TestReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, dir, "first")
+	r, err := NewReloader(config.TLSConfig{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		MinVersion:   "1.3",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name := servedName(t, r); name != "first" {
+		t.Errorf("expected the first certificate, got %s", name)
+	}
+
+	writeCertificate(t, dir, "second")
+	r.Reload()
+	if name := servedName(t, r); name != "second" {
+		t.Errorf("expected the rotated certificate, got %s", name)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "tls.key"), []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r.Reload()
+	if name := servedName(t, r); name != "second" {
+		t.Errorf("expected an invalid key to keep the current certificate, got %s", name)
+	}
+}
+
+func TestReloader_Start(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, dir, "first")
+	r, err := NewReloader(config.TLSConfig{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer r.Stop()
+
+	writeCertificate(t, dir, "second")
+	deadline := time.Now().Add(5 * time.Second)
+	for servedName(t, r) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the rotated certificate to be picked up")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestNewReloader_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeCertificate(t, dir, "proxy")
+
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+	}{
+		{name: "missing cert", cfg: config.TLSConfig{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "tls.key")}},
+		{name: "key as cert", cfg: config.TLSConfig{CertFile: filepath.Join(dir, "tls.key"), KeyFile: filepath.Join(dir, "tls.key")}},
+		{name: "key as client CA", cfg: config.TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key"), ClientCAFile: filepath.Join(dir, "tls.key")}},
+		{name: "min version", cfg: config.TLSConfig{CertFile: filepath.Join(dir, "tls.crt"), KeyFile: filepath.Join(dir, "tls.key"), MinVersion: "1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewReloader(tt.cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -17,6 +17,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// minCleanupInterval bounds how often expired entries are removed, however
+// short the TTL.
+const minCleanupInterval = time.Second
+
 type cacheEntry struct {
 	data      interface{}
 	timestamp time.Time
@@ -27,6 +31,9 @@ type memoryCache struct {
 	mutex   sync.RWMutex
 	ttl     time.Duration
 	maxSize int
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func newMemoryCache(ttl time.Duration, maxSize int) *memoryCache {
@@ -34,6 +41,7 @@ func newMemoryCache(ttl time.Duration, maxSize int) *memoryCache {
 		entries: make(map[string]*cacheEntry),
 		ttl:     ttl,
 		maxSize: maxSize,
+		stop:    make(chan struct{}),
 	}
 
 	go cache.cleanup()
@@ -77,6 +85,13 @@ func (mc *memoryCache) delete(key string) {
 	delete(mc.entries, key)
 }
 
+// setTTL changes the TTL of cached and future entries.
+func (mc *memoryCache) setTTL(ttl time.Duration) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	mc.ttl = ttl
+}
+
 func (mc *memoryCache) evictLRU() {
 	var oldestKey string
 	var oldestTime time.Time
@@ -94,12 +109,30 @@ func (mc *memoryCache) evictLRU() {
 	}
 }
 
+// cleanupInterval returns how often entries expiring after ttl are removed.
+func cleanupInterval(ttl time.Duration) time.Duration {
+	return max(ttl/2, minCleanupInterval)
+}
+
 func (mc *memoryCache) cleanup() {
-	ticker := time.NewTicker(mc.ttl / 2)
+	mc.mutex.RLock()
+	interval := cleanupInterval(mc.ttl)
+	mc.mutex.RUnlock()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-mc.stop:
+			return
+		case <-ticker.C:
+		}
+
 		mc.mutex.Lock()
+		if next := cleanupInterval(mc.ttl); next != interval {
+			interval = next
+			ticker.Reset(interval)
+		}
 		now := time.Now()
 		expiredCount := 0
 		for key, entry := range mc.entries {
@@ -119,6 +152,11 @@ func (mc *memoryCache) cleanup() {
 	}
 }
 
+// close stops the cleanup goroutine.
+func (mc *memoryCache) close() {
+	mc.stopOnce.Do(func() { close(mc.stop) })
+}
+
 func (mc *memoryCache) size() int {
 	mc.mutex.RLock()
 	defer mc.mutex.RUnlock()
@@ -155,6 +193,22 @@ func NewArtifactHubClient(cfg config.ArtifactHubConfig) *ArtifactHubClient {
 	return client
 }
 
+// SetCacheTTL changes the TTL of the response cache, entries already cached
+// expire by the new TTL.
+func (c *ArtifactHubClient) SetCacheTTL(ttl time.Duration) {
+	if c.cache != nil {
+		c.cache.setTTL(ttl)
+	}
+}
+
+// Close stops the cache cleanup of the client.
+func (c *ArtifactHubClient) Close() error {
+	if c.cache != nil {
+		c.cache.close()
+	}
+	return nil
+}
+
 func (c *ArtifactHubClient) generateCacheKey(prefix string, params ...string) string {
 	key := prefix
 	for _, param := range params {
@@ -358,6 +412,113 @@ func (c *ArtifactHubClient) SearchPackages(params SearchParams) (*models.Artifac
 	return &response, nil
 }
 
+// GetRepository returns the Artifact Hub repository with the given name.
+func (c *ArtifactHubClient) GetRepository(name string) (*models.ArtifactHubRepository, error) {
+	if c.cache != nil {
+		cacheKey := c.generateCacheKey("repository", name)
+
+		if cached, found := c.cache.get(cacheKey); found {
+			logrus.WithFields(logrus.Fields{
+				"api_call":   "GetRepository",
+				"repository": name,
+			}).Info("🚀 CACHE HIT - GetRepository")
+			return cached.(*models.ArtifactHubRepository), nil
+		}
+	}
+
+	queryParams := url.Values{}
+	queryParams.Set("name", name)
+	url := c.baseURL + "/api/v1/repositories/search?" + queryParams.Encode()
+
+	logrus.WithFields(logrus.Fields{
+		"api_call":   "GetRepository",
+		"repository": name,
+		"url":        url,
+	}).Debug("🌐 Making Artifact Hub API call")
+
+	var repositories []models.ArtifactHubRepository
+	if err := c.makeRequest("GET", url, &repositories); err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	var response *models.ArtifactHubRepository
+	for i := range repositories {
+		if repositories[i].Name == name {
+			response = &repositories[i]
+			break
+		}
+	}
+	if response == nil {
+		return nil, fmt.Errorf("repository %q: %w", name, ErrNotFound)
+	}
+
+	if c.cache != nil {
+		cacheKey := c.generateCacheKey("repository", name)
+		c.cache.set(cacheKey, response)
+		logrus.WithFields(logrus.Fields{
+			"api_call":   "GetRepository",
+			"status":     "success",
+			"repository": response.Name,
+			"cache_size": c.cache.size(),
+		}).Info("📦 API CALL CACHED - GetRepository")
+	} else {
+		logrus.WithFields(logrus.Fields{
+			"api_call":   "GetRepository",
+			"status":     "success",
+			"repository": response.Name,
+		}).Info("🌐 API CALL NO CACHE - GetRepository")
+	}
+
+	return response, nil
+}
+
+// SearchRepositories returns every Artifact Hub repository matching the
+// parameters, following pagination. Results are not cached, callers poll
+// for changes.
+func (c *ArtifactHubClient) SearchRepositories(params RepositorySearchParams) ([]models.ArtifactHubRepository, error) {
+	var repositories []models.ArtifactHubRepository
+
+	for offset := 0; ; offset += repositoryPageSize {
+		queryParams := url.Values{}
+		for _, kind := range params.Kinds {
+			queryParams.Add("kind", strconv.Itoa(kind))
+		}
+		for _, org := range params.Organizations {
+			queryParams.Add("org", org)
+		}
+		for _, user := range params.Users {
+			queryParams.Add("user", user)
+		}
+		queryParams.Set("limit", strconv.Itoa(repositoryPageSize))
+		queryParams.Set("offset", strconv.Itoa(offset))
+		url := c.baseURL + "/api/v1/repositories/search?" + queryParams.Encode()
+
+		logrus.WithFields(logrus.Fields{
+			"api_call": "SearchRepositories",
+			"offset":   offset,
+			"url":      url,
+		}).Debug("🌐 Making Artifact Hub API call")
+
+		var page []models.ArtifactHubRepository
+		if err := c.makeRequest("GET", url, &page); err != nil {
+			return nil, fmt.Errorf("failed to search repositories: %w", err)
+		}
+		repositories = append(repositories, page...)
+
+		if len(page) < repositoryPageSize {
+			break
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"api_call": "SearchRepositories",
+		"status":   "success",
+		"results":  len(repositories),
+	}).Info("🌐 API CALL NO CACHE - SearchRepositories")
+
+	return repositories, nil
+}
+
 func (c *ArtifactHubClient) makeRequest(method, url string, result interface{}) error {
 	var lastErr error
 
@@ -398,6 +559,9 @@ func (c *ArtifactHubClient) makeRequest(method, url string, result interface{})
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+			if resp.StatusCode == http.StatusNotFound {
+				lastErr = fmt.Errorf("HTTP %d: %s: %w", resp.StatusCode, string(body), ErrNotFound)
+			}
 
 			// Don't retry on client errors (4xx)
 			if resp.StatusCode >= 400 && resp.StatusCode < 500 {
@@ -418,6 +582,18 @@ func (c *ArtifactHubClient) makeRequest(method, url string, result interface{})
 	return lastErr
 }
 
+// repositoryPageSize is the largest page Artifact Hub's repository search
+// returns.
+const repositoryPageSize = 60
+
+// RepositorySearchParams filters a repository search. Values of one filter
+// are combined with OR.
+type RepositorySearchParams struct {
+	Kinds         []int
+	Organizations []string
+	Users         []string
+}
+
 type SearchParams struct {
 	Query        string
 	Kinds        []int
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCleanupInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{name: "half the ttl", ttl: 10 * time.Minute, want: 5 * time.Minute},
+		{name: "short ttl", ttl: time.Nanosecond, want: minCleanupInterval},
+		{name: "zero ttl", ttl: 0, want: minCleanupInterval},
+		{name: "negative ttl", ttl: -time.Second, want: minCleanupInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanupInterval(tt.ttl); got != tt.want {
+				t.Errorf("cleanupInterval(%s) = %s, want %s", tt.ttl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryCache_SetTTLKeepsCleanupRunning(t *testing.T) {
+	cache := newMemoryCache(time.Nanosecond, 10)
+	defer cache.close()
+	cache.set("key", "value")
+
+	// A cleanup tick after the TTL change used to reset the ticker to 0
+	// and panic
+	cache.setTTL(0)
+	time.Sleep(minCleanupInterval + 200*time.Millisecond)
+
+	if size := cache.size(); size != 0 {
+		t.Errorf("expected the expired entry to be cleaned up, %d left", size)
+	}
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"errors"
+
+	"tekton-hub-proxy/internal/models"
+)
+
+// ErrNotFound is returned when a source doesn't have the requested package,
+// version or repository. Other errors, such as upstream failures, mean the
+// source couldn't tell.
+var ErrNotFound = errors.New("not found")
+
+// PackageSource answers Artifact Hub package lookups. ArtifactHubClient
+// implements it against the live API, snapshot stores implement it offline.
+type PackageSource interface {
+	GetPackage(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error)
+	GetPackageLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error)
+	SearchPackages(params SearchParams) (*models.ArtifactHubSearchResponse, error)
+}
+
+// RepositorySource answers Artifact Hub repository lookups. Package sources
+// without repository metadata, such as snapshots, don't implement it.
+type RepositorySource interface {
+	GetRepository(name string) (*models.ArtifactHubRepository, error)
+	SearchRepositories(params RepositorySearchParams) ([]models.ArtifactHubRepository, error)
+}
+
+var (
+	_ PackageSource    = (*ArtifactHubClient)(nil)
+	_ RepositorySource = (*ArtifactHubClient)(nil)
+)
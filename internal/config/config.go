@@ -2,27 +2,281 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
+// Config is the proxy configuration. Fields holding secrets are tagged
+// secret:"true" so the effective configuration redacts them.
 type Config struct {
 	Server          ServerConfig             `mapstructure:"server"`
 	ArtifactHub     ArtifactHubConfig        `mapstructure:"artifacthub"`
 	CatalogMappings []CatalogMapping         `mapstructure:"catalog_mappings"`
 	Logging         LoggingConfig            `mapstructure:"logging"`
 	LandingPage     LandingPageConfig        `mapstructure:"landing_page"`
+	Mirror          MirrorConfig             `mapstructure:"mirror"`
+	Kinds           []KindMapping            `mapstructure:"kinds"`
+	Versions        VersionsConfig           `mapstructure:"versions"`
+	Categories      []CategoryMapping        `mapstructure:"categories"`
+	Aliases         []ResourceAlias          `mapstructure:"aliases"`
+	Discovery       DiscoveryConfig          `mapstructure:"discovery"`
+	Policy          PolicyConfig             `mapstructure:"policy"`
+	// StrictCatalogs answers 404 for catalogs missing from the mappings
+	// instead of passing their names through to Artifact Hub.
+	StrictCatalogs bool `mapstructure:"strict_catalogs"`
+
+	// Sources records where each setting came from.
+	Sources Sources `mapstructure:"-"`
+}
+
+// ResourceAlias redirects requests for a renamed resource. An empty catalog
+// or kind matches any, empty fields of To keep the requested value.
+// Versions limits the alias to requested versions within a range.
+type ResourceAlias struct {
+	Catalog  string       `mapstructure:"catalog"`
+	Kind     string       `mapstructure:"kind"`
+	Name     string       `mapstructure:"name"`
+	Versions string       `mapstructure:"versions"`
+	To       ResourceName `mapstructure:"to"`
+}
+
+// Policy actions.
+const (
+	PolicyAllow = "allow"
+	PolicyDeny  = "deny"
+)
+
+// PolicyConfig decides which resources can be resolved. The first rule
+// matching a resource applies, Default when none does.
+type PolicyConfig struct {
+	Default string       `mapstructure:"default"`
+	Rules   []PolicyRule `mapstructure:"rules"`
+}
+
+// PolicyRule matches resources by every condition it sets. Names are globs,
+// Versions a version range, unset flags match any package.
+type PolicyRule struct {
+	Name              string   `mapstructure:"name"`
+	Action            string   `mapstructure:"action"`
+	Catalogs          []string `mapstructure:"catalogs"`
+	Kinds             []string `mapstructure:"kinds"`
+	Names             []string `mapstructure:"names"`
+	Versions          string   `mapstructure:"versions"`
+	Signed            *bool    `mapstructure:"signed"`
+	Deprecated        *bool    `mapstructure:"deprecated"`
+	VerifiedPublisher *bool    `mapstructure:"verified_publisher"`
+	Reason            string   `mapstructure:"reason"`
+}
+
+// ResourceName names a resource by its Tekton Hub coordinates.
+type ResourceName struct {
+	Catalog string `mapstructure:"catalog"`
+	Kind    string `mapstructure:"kind"`
+	Name    string `mapstructure:"name"`
+}
+
+// CategoryMapping is a Tekton Hub category and the Artifact Hub keywords
+// filed under it. Resources naming the category in their
+// tekton.dev/categories annotation, or having one of the keywords, are in
+// the category.
+type CategoryMapping struct {
+	ID       int      `mapstructure:"id"`
+	Name     string   `mapstructure:"name"`
+	Keywords []string `mapstructure:"keywords"`
+}
+
+// DefaultCategories returns the Tekton Hub category list.
+func DefaultCategories() []CategoryMapping {
+	return []CategoryMapping{
+		{ID: 1, Name: "Automation", Keywords: []string{"automation", "ansible"}},
+		{ID: 2, Name: "Build Tools", Keywords: []string{"build", "buildah", "buildpacks", "kaniko", "maven", "gradle", "npm", "make", "golang-build"}},
+		{ID: 3, Name: "CLI", Keywords: []string{"cli", "tkn", "kubectl", "oc"}},
+		{ID: 4, Name: "Cloud", Keywords: []string{"cloud", "aws", "azure", "gcp", "gcloud", "ibmcloud"}},
+		{ID: 5, Name: "Code Quality", Keywords: []string{"lint", "linter", "sonarqube", "golangci-lint", "code-quality"}},
+		{ID: 6, Name: "Continuous Integration", Keywords: []string{"ci", "github", "gitlab"}},
+		{ID: 7, Name: "Deployment", Keywords: []string{"deploy", "deployment", "helm", "kustomize", "argocd"}},
+		{ID: 8, Name: "Developer Tools", Keywords: []string{"developer-tools", "tools"}},
+		{ID: 9, Name: "Image Build", Keywords: []string{"image-build", "image", "docker", "container", "oci"}},
+		{ID: 10, Name: "Integration & Delivery", Keywords: []string{"cd", "delivery", "integration"}},
+		{ID: 11, Name: "Git", Keywords: []string{"git", "scm", "git-clone"}},
+		{ID: 12, Name: "Kubernetes", Keywords: []string{"kubernetes", "k8s"}},
+		{ID: 13, Name: "Messaging", Keywords: []string{"messaging", "slack", "email", "notification"}},
+		{ID: 14, Name: "Monitoring", Keywords: []string{"monitoring", "prometheus"}},
+		{ID: 15, Name: "Networking", Keywords: []string{"networking", "network"}},
+		{ID: 16, Name: "Openshift", Keywords: []string{"openshift"}},
+		{ID: 17, Name: "Publishing", Keywords: []string{"publishing", "publish", "release"}},
+		{ID: 18, Name: "Security", Keywords: []string{"security", "scan", "trivy", "sign", "cosign"}},
+		{ID: 19, Name: "Storage", Keywords: []string{"storage", "s3", "gcs"}},
+		{ID: 20, Name: "Testing", Keywords: []string{"test", "testing", "e2e"}},
+	}
+}
+
+// KindMapping ties a Tekton Hub kind to its Artifact Hub repository kind,
+// both the numeric ID used by searches and the name used in package URLs.
+type KindMapping struct {
+	Name           string `mapstructure:"name"`
+	RepositoryKind int    `mapstructure:"repository_kind"`
+	RepoKind       string `mapstructure:"repo_kind"`
 }
 
+// DefaultKinds returns the Tekton repository kinds known to Artifact Hub.
+func DefaultKinds() []KindMapping {
+	return []KindMapping{
+		{Name: "task", RepositoryKind: 7, RepoKind: "tekton-task"},
+		{Name: "pipeline", RepositoryKind: 11, RepoKind: "tekton-pipeline"},
+		{Name: "stepaction", RepositoryKind: 23, RepoKind: "tekton-stepaction"},
+	}
+}
+
+// Backends a catalog mapping can be served from.
+const (
+	BackendArtifactHub = "artifacthub"
+	BackendFilesystem  = "filesystem"
+	BackendGit         = "git"
+)
+
+// Kinds of catalog mapping patterns. Glob patterns capture each "*" of
+// tekton_hub and substitute them for the "*"s of the Artifact Hub names in
+// order. Regex patterns substitute $1 or ${1} style references.
+const (
+	PatternGlob  = "glob"
+	PatternRegex = "regex"
+)
+
+// CatalogTarget is a catalog served by one backend.
+type CatalogTarget struct {
+	ArtifactHub string `mapstructure:"artifact_hub" json:"artifact_hub"`
+	Backend     string `mapstructure:"backend" json:"backend,omitempty"`
+	Path        string `mapstructure:"path" json:"path,omitempty"`
+
+	// Artifact Hub instance other than artifacthub.base_url
+	BaseURL string `mapstructure:"base_url" json:"base_url,omitempty"`
+
+	// Git backend settings
+	URL             string        `mapstructure:"url" json:"url,omitempty"`
+	Ref             string        `mapstructure:"ref" json:"ref,omitempty"`
+	Versions        string        `mapstructure:"versions" json:"versions,omitempty"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" json:"refresh_interval,omitempty"`
+	Timeout         time.Duration `mapstructure:"timeout" json:"timeout,omitempty"`
+}
+
+// BackendName returns the backend serving the target, Artifact Hub unless
+// configured otherwise.
+func (t CatalogTarget) BackendName() string {
+	if t.Backend == "" {
+		return BackendArtifactHub
+	}
+	return t.Backend
+}
+
+// CatalogMapping maps a Tekton Hub catalog to one target, set inline, or to
+// an ordered list of targets where the first one having a resource answers.
+// A kind scopes the mapping to one Tekton kind, a pattern makes tekton_hub
+// a glob or regex matching many catalogs.
 type CatalogMapping struct {
-	TektonHub    string `mapstructure:"tekton_hub"`
-	ArtifactHub  string `mapstructure:"artifact_hub"`
+	TektonHub   string `mapstructure:"tekton_hub" json:"tekton_hub"`
+	Kind        string `mapstructure:"kind" json:"kind,omitempty"`
+	Pattern     string `mapstructure:"pattern" json:"pattern,omitempty"`
+	ArtifactHub string `mapstructure:"artifact_hub" json:"artifact_hub,omitempty"`
+	Backend     string `mapstructure:"backend" json:"backend,omitempty"`
+	Path        string `mapstructure:"path" json:"path,omitempty"`
+	BaseURL     string `mapstructure:"base_url" json:"base_url,omitempty"`
+
+	URL             string        `mapstructure:"url" json:"url,omitempty"`
+	Ref             string        `mapstructure:"ref" json:"ref,omitempty"`
+	Versions        string        `mapstructure:"versions" json:"versions,omitempty"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" json:"refresh_interval,omitempty"`
+	Timeout         time.Duration `mapstructure:"timeout" json:"timeout,omitempty"`
+
+	Targets []CatalogTarget `mapstructure:"targets" json:"targets,omitempty"`
+
+	// Catalog overrides the metadata served by /v1/catalogs
+	Catalog CatalogMetadata `mapstructure:"catalog" json:"catalog,omitempty"`
+}
+
+// CatalogMetadata overrides the catalog metadata read from the repository of
+// the first target. Empty fields keep the repository's value.
+type CatalogMetadata struct {
+	ID          int    `mapstructure:"id" json:"id,omitempty"`
+	DisplayName string `mapstructure:"display_name" json:"display_name,omitempty"`
+	Provider    string `mapstructure:"provider" json:"provider,omitempty"`
+	Type        string `mapstructure:"type" json:"type,omitempty"`
+	URL         string `mapstructure:"url" json:"url,omitempty"`
+}
+
+// AllTargets returns the targets of the mapping in lookup order. Targets
+// without a catalog name use the Tekton Hub name.
+func (m CatalogMapping) AllTargets() []CatalogTarget {
+	targets := m.Targets
+	if len(targets) == 0 {
+		targets = []CatalogTarget{{
+			ArtifactHub:     m.ArtifactHub,
+			Backend:         m.Backend,
+			Path:            m.Path,
+			BaseURL:         m.BaseURL,
+			URL:             m.URL,
+			Ref:             m.Ref,
+			Versions:        m.Versions,
+			RefreshInterval: m.RefreshInterval,
+			Timeout:         m.Timeout,
+		}}
+	}
+
+	resolved := make([]CatalogTarget, len(targets))
+	for i, target := range targets {
+		if target.ArtifactHub == "" {
+			target.ArtifactHub = m.TektonHub
+		}
+		resolved[i] = target
+	}
+	return resolved
+}
+
+// TargetCatalog returns the catalog name of the first target.
+func (m CatalogMapping) TargetCatalog() string {
+	return m.AllTargets()[0].ArtifactHub
 }
 
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+	// ShutdownTimeout bounds draining the requests in flight on SIGTERM,
+	// ShutdownDelay keeps serving them while readiness fails before
+	// listeners close, so load balancers stop sending new ones.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay"`
+	TLS             TLSConfig     `mapstructure:"tls"`
+	Admin           AdminConfig   `mapstructure:"admin"`
+}
+
+// AdminConfig serves the admin endpoints, which describe backends, git
+// remotes, paths and policy rules, on the proxy listener.
+type AdminConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// Client authentication modes of TLSConfig.
+const (
+	ClientAuthRequire       = "require"
+	ClientAuthVerifyIfGiven = "verify_if_given"
+)
+
+// TLSConfig serves HTTPS. The files are reloaded when they change, a client
+// CA enables mutual TLS.
+type TLSConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CertFile     string `mapstructure:"cert_file"`
+	KeyFile      string `mapstructure:"key_file"`
+	ClientCAFile string `mapstructure:"client_ca_file"`
+	// ClientAuth is require, or verify_if_given to also accept clients
+	// without a certificate such as probes.
+	ClientAuth string `mapstructure:"client_auth"`
+	// MinVersion is 1.2 or 1.3.
+	MinVersion string `mapstructure:"min_version"`
 }
 
 type ArtifactHubConfig struct {
@@ -38,6 +292,42 @@ type CacheConfig struct {
 	MaxSize  int           `mapstructure:"max_size"`
 }
 
+type MirrorConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	Path     string        `mapstructure:"path"`
+}
+
+// DiscoveryConfig maps the Tekton repositories found on Artifact Hub to
+// catalogs of the same name. Configured mappings win over discovered ones.
+type DiscoveryConfig struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	Interval time.Duration `mapstructure:"interval"`
+	// Organizations and Users limit discovery to repositories published by
+	// them, any publisher when both are empty.
+	Organizations []string `mapstructure:"organizations"`
+	Users         []string `mapstructure:"users"`
+	// VerifiedOnly and OfficialOnly skip repositories without a verified
+	// publisher or official status.
+	VerifiedOnly bool `mapstructure:"verified_only"`
+	OfficialOnly bool `mapstructure:"official_only"`
+}
+
+// VersionsConfig controls how requested versions map to the versions a
+// package has.
+type VersionsConfig struct {
+	// SimplifiedPolicy resolves x.y versions: exact_first, exact or
+	// highest_patch.
+	SimplifiedPolicy string `mapstructure:"simplified_policy"`
+	// PipelinesVersion is the Tekton Pipelines version assumed for clients
+	// not sending theirs.
+	PipelinesVersion string `mapstructure:"pipelines_version"`
+	// ExcludePrereleases and ExcludeDeprecated hide versions from latest
+	// resolution and version listings.
+	ExcludePrereleases bool `mapstructure:"exclude_prereleases"`
+	ExcludeDeprecated  bool `mapstructure:"exclude_deprecated"`
+}
+
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
 	Format string `mapstructure:"format"`
@@ -47,6 +337,11 @@ type LandingPageConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// envPrefix prefixes the environment variables overriding settings.
+const envPrefix = "THP"
+
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
 func Load() (*Config, error) {
 	return LoadWithPath("")
 }
@@ -62,13 +357,21 @@ func LoadWithPath(configPath string) (*Config, error) {
 		viper.AddConfigPath(".")
 	}
 
-	// Set environment variable prefix
-	viper.SetEnvPrefix("THP")
+	// Set environment variable prefix, THP_ARTIFACTHUB_CACHE_TTL overrides
+	// artifacthub.cache.ttl
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
 	// Set defaults
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.host", "0.0.0.0")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.shutdown_delay", "0s")
+	viper.SetDefault("server.tls.enabled", false)
+	viper.SetDefault("server.admin.enabled", false)
+	viper.SetDefault("server.tls.client_auth", ClientAuthRequire)
+	viper.SetDefault("server.tls.min_version", "1.2")
 	viper.SetDefault("artifacthub.base_url", "https://artifacthub.io")
 	viper.SetDefault("artifacthub.timeout", "30s")
 	viper.SetDefault("artifacthub.max_retries", 3)
@@ -78,15 +381,66 @@ func LoadWithPath(configPath string) (*Config, error) {
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
 	viper.SetDefault("landing_page.enabled", true)
+	viper.SetDefault("strict_catalogs", false)
+	viper.SetDefault("discovery.enabled", false)
+	viper.SetDefault("discovery.interval", "1h")
+	viper.SetDefault("policy.default", PolicyAllow)
+	viper.SetDefault("mirror.enabled", false)
+	viper.SetDefault("mirror.interval", "30m")
+	viper.SetDefault("versions.simplified_policy", "exact_first")
+	viper.SetDefault("versions.exclude_prereleases", true)
+	viper.SetDefault("versions.exclude_deprecated", false)
 
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	return unmarshal()
+}
+
+// watchDelay lets writes of a config file settle before it is reloaded, so
+// files written in several steps aren't read half written.
+const watchDelay = 500 * time.Millisecond
+
+// Watch calls onChange with the reloaded configuration, or the error
+// reading it, whenever the config file read by LoadWithPath changes.
+func Watch(onChange func(*Config, error)) {
+	var mutex sync.Mutex
+	var timer *time.Timer
+	reload := func() {
+		// Viper keeps the previous values of unreadable files without
+		// reporting it, read again for the error
+		if err := viper.ReadInConfig(); err != nil {
+			onChange(nil, fmt.Errorf("failed to read config file: %w", err))
+			return
+		}
+		onChange(unmarshal())
+	}
+
+	viper.OnConfigChange(func(fsnotify.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(watchDelay, reload)
+	})
+	viper.WatchConfig()
+}
+
+func unmarshal() (*Config, error) {
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if len(config.Kinds) == 0 {
+		config.Kinds = DefaultKinds()
+	}
+	if len(config.Categories) == 0 {
+		config.Categories = DefaultCategories()
+	}
+	config.Sources = loadedSources()
+
 	return &config, nil
 }
\ No newline at end of file
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change summarizes a changed configuration section.
+type Change struct {
+	Section string `json:"section"`
+	Summary string `json:"summary"`
+}
+
+func (c Change) String() string {
+	return c.Section + ": " + c.Summary
+}
+
+// Diff returns the sections changed between two configurations, in
+// configuration order.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	changed := func(section string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			changes = append(changes, Change{Section: section, Summary: "changed"})
+		}
+	}
+	scalar := func(section string, a, b any) {
+		if a != b {
+			changes = append(changes, Change{Section: section, Summary: fmt.Sprintf("%v -> %v", a, b)})
+		}
+	}
+
+	changed("server", old.Server, new.Server)
+	oldArtifactHub, newArtifactHub := old.ArtifactHub, new.ArtifactHub
+	oldArtifactHub.Cache.TTL, newArtifactHub.Cache.TTL = 0, 0
+	changed("artifacthub", oldArtifactHub, newArtifactHub)
+	scalar("artifacthub.cache.ttl", old.ArtifactHub.Cache.TTL, new.ArtifactHub.Cache.TTL)
+	if summary := diffMappings(old.CatalogMappings, new.CatalogMappings); summary != "" {
+		changes = append(changes, Change{Section: "catalog_mappings", Summary: summary})
+	}
+	scalar("logging.level", old.Logging.Level, new.Logging.Level)
+	scalar("logging.format", old.Logging.Format, new.Logging.Format)
+	changed("landing_page", old.LandingPage, new.LandingPage)
+	changed("mirror", old.Mirror, new.Mirror)
+	changed("kinds", old.Kinds, new.Kinds)
+	changed("versions", old.Versions, new.Versions)
+	changed("categories", old.Categories, new.Categories)
+	if !reflect.DeepEqual(old.Aliases, new.Aliases) {
+		changes = append(changes, Change{Section: "aliases", Summary: fmt.Sprintf("%d -> %d aliases", len(old.Aliases), len(new.Aliases))})
+	}
+	changed("discovery", old.Discovery, new.Discovery)
+	if !reflect.DeepEqual(old.Policy, new.Policy) {
+		var parts []string
+		if old.Policy.Default != new.Policy.Default {
+			parts = append(parts, fmt.Sprintf("default %s -> %s", old.Policy.Default, new.Policy.Default))
+		}
+		if !reflect.DeepEqual(old.Policy.Rules, new.Policy.Rules) {
+			parts = append(parts, fmt.Sprintf("%d -> %d rules", len(old.Policy.Rules), len(new.Policy.Rules)))
+		}
+		changes = append(changes, Change{Section: "policy", Summary: strings.Join(parts, ", ")})
+	}
+	scalar("strict_catalogs", old.StrictCatalogs, new.StrictCatalogs)
+	return changes
+}
+
+// diffMappings names the added, removed and changed mappings, identified by
+// catalog and kind.
+func diffMappings(old, new []CatalogMapping) string {
+	key := func(mapping CatalogMapping) string {
+		if mapping.Kind != "" {
+			return mapping.TektonHub + "/" + strings.ToLower(mapping.Kind)
+		}
+		return mapping.TektonHub
+	}
+	oldByKey := make(map[string]CatalogMapping)
+	for _, mapping := range old {
+		oldByKey[key(mapping)] = mapping
+	}
+
+	var added, changed []string
+	seen := make(map[string]bool)
+	for _, mapping := range new {
+		k := key(mapping)
+		seen[k] = true
+		previous, exists := oldByKey[k]
+		switch {
+		case !exists:
+			added = append(added, k)
+		case !reflect.DeepEqual(previous, mapping):
+			changed = append(changed, k)
+		}
+	}
+	var removed []string
+	for _, mapping := range old {
+		if k := key(mapping); !seen[k] {
+			removed = append(removed, k)
+		}
+	}
+
+	var parts []string
+	for _, part := range []struct {
+		verb  string
+		names []string
+	}{{"added", added}, {"removed", removed}, {"changed", changed}} {
+		if len(part.names) > 0 {
+			parts = append(parts, part.verb+" "+strings.Join(part.names, ", "))
+		}
+	}
+	if len(parts) == 0 && !reflect.DeepEqual(old, new) {
+		// Same mappings in another order, which decides pattern precedence
+		return "reordered"
+	}
+	return strings.Join(parts, "; ")
+}
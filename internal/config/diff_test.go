@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		ArtifactHub: ArtifactHubConfig{BaseURL: "https://artifacthub.io", Cache: CacheConfig{TTL: time.Hour}},
+		CatalogMappings: []CatalogMapping{
+			{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+			{TektonHub: "tekton", Kind: "pipeline", ArtifactHub: "tekton-catalog-pipelines"},
+			{TektonHub: "community", ArtifactHub: "tekton-community"},
+		},
+		Logging: LoggingConfig{Level: "info", Format: "json"},
+		Policy:  PolicyConfig{Default: PolicyAllow},
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(*Config)
+		expected []Change
+	}{
+		{name: "unchanged", modify: func(c *Config) {}},
+		{
+			name: "reloadable settings",
+			modify: func(c *Config) {
+				c.ArtifactHub.Cache.TTL = 5 * time.Minute
+				c.Logging.Level = "debug"
+				c.Policy.Rules = []PolicyRule{{Action: PolicyDeny}}
+				c.StrictCatalogs = true
+			},
+			expected: []Change{
+				{Section: "artifacthub.cache.ttl", Summary: "1h0m0s -> 5m0s"},
+				{Section: "logging.level", Summary: "info -> debug"},
+				{Section: "policy", Summary: "0 -> 1 rules"},
+				{Section: "strict_catalogs", Summary: "false -> true"},
+			},
+		},
+		{
+			name: "mappings",
+			modify: func(c *Config) {
+				c.CatalogMappings = []CatalogMapping{
+					{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+					{TektonHub: "tekton", Kind: "pipeline", ArtifactHub: "my-pipelines"},
+					{TektonHub: "local", Backend: BackendFilesystem, Path: "/catalog"},
+				}
+			},
+			expected: []Change{{Section: "catalog_mappings", Summary: "added local; removed community; changed tekton/pipeline"}},
+		},
+		{
+			name: "reordered mappings",
+			modify: func(c *Config) {
+				c.CatalogMappings = []CatalogMapping{c.CatalogMappings[2], c.CatalogMappings[0], c.CatalogMappings[1]}
+			},
+			expected: []Change{{Section: "catalog_mappings", Summary: "reordered"}},
+		},
+		{
+			name:     "base url",
+			modify:   func(c *Config) { c.ArtifactHub.BaseURL = "https://hub.example.com" },
+			expected: []Change{{Section: "artifacthub", Summary: "changed"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := *old
+			updated.CatalogMappings = append([]CatalogMapping{}, old.CatalogMappings...)
+			tt.modify(&updated)
+			if changes := Diff(old, &updated); !reflect.DeepEqual(changes, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Sources of configuration values.
+const (
+	SourceDefault  = "default"
+	SourceFile     = "file"
+	SourceEnv      = "env"
+	SourceFlag     = "flag"
+	SourceSnapshot = "snapshot"
+)
+
+// redacted replaces secret values in the effective configuration.
+const redacted = "xxxxx"
+
+// Sources maps setting keys such as artifacthub.cache.ttl to the source of
+// their values.
+type Sources map[string]string
+
+// Of returns the source of a setting, the source of the closest parent
+// setting for keys within lists and the default for settings nothing set.
+func (s Sources) Of(key string) string {
+	for {
+		if source, ok := s[key]; ok {
+			return source
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return SourceDefault
+		}
+		key = key[:i]
+	}
+}
+
+// Update returns a copy of the sources with the settings of sections, and
+// the settings within them, taken from next.
+func (s Sources) Update(next Sources, sections ...string) Sources {
+	within := func(key string) bool {
+		for _, section := range sections {
+			if key == section || strings.HasPrefix(key, section+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	updated := make(Sources, len(s))
+	for key, source := range s {
+		if !within(key) {
+			updated[key] = source
+		}
+	}
+	for key, source := range next {
+		if within(key) {
+			updated[key] = source
+		}
+	}
+	return updated
+}
+
+// SetSource records where the value of a setting came from, for values
+// changed after loading.
+func (c *Config) SetSource(key, source string) {
+	if c.Sources == nil {
+		c.Sources = make(Sources)
+	}
+	c.Sources[key] = source
+}
+
+// FileUsed returns the path of the config file read by LoadWithPath.
+func FileUsed() string {
+	return viper.ConfigFileUsed()
+}
+
+// envName returns the environment variable overriding a setting.
+func envName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(envKeyReplacer.Replace(key))
+}
+
+// loadedSources returns the sources of the settings viper knows about.
+func loadedSources() Sources {
+	sources := make(Sources)
+	for _, key := range viper.AllKeys() {
+		switch {
+		case os.Getenv(envName(key)) != "":
+			sources[key] = SourceEnv
+		case viper.InConfig(key):
+			sources[key] = SourceFile
+		default:
+			sources[key] = SourceDefault
+		}
+	}
+	return sources
+}
+
+// Effective is the configuration the proxy runs with and the source of
+// each setting, with secrets redacted.
+type Effective struct {
+	Config  map[string]any `json:"config"`
+	Sources Sources        `json:"sources"`
+}
+
+// Effective returns the configuration keyed like the config file. Lists are
+// settings of their own, their items are listed without empty fields.
+func (c *Config) Effective() Effective {
+	document, sources := c.document()
+	effective := Effective{Sources: sources}
+	// Decoding a document built from the configuration can't fail
+	_ = document.Decode(&effective.Config)
+	return effective
+}
+
+// EffectiveYAML renders the effective configuration as YAML, each setting
+// commented with its source.
+func (c *Config) EffectiveYAML() ([]byte, error) {
+	document, _ := c.document()
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// document builds the YAML document of the configuration. Fields tagged
+// secret:"true" and passwords in URLs are redacted.
+func (c *Config) document() (*yaml.Node, Sources) {
+	sources := make(Sources)
+
+	var build func(value reflect.Value, key string, inList bool) *yaml.Node
+	build = func(value reflect.Value, key string, inList bool) *yaml.Node {
+		switch value.Kind() {
+		case reflect.Struct:
+			node := &yaml.Node{Kind: yaml.MappingNode}
+			for i := 0; i < value.NumField(); i++ {
+				field := value.Type().Field(i)
+				name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+				fieldValue := value.Field(i)
+				if name == "" || name == "-" || (inList && fieldValue.IsZero()) {
+					continue
+				}
+				fieldKey := name
+				if key != "" {
+					fieldKey = key + "." + name
+				}
+
+				var valueNode *yaml.Node
+				if field.Tag.Get("secret") == "true" && !fieldValue.IsZero() {
+					valueNode = &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}
+				} else {
+					valueNode = build(fieldValue, fieldKey, inList)
+				}
+				keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+				if !inList && valueNode.Kind != yaml.MappingNode {
+					sources[fieldKey] = c.Sources.Of(fieldKey)
+					if valueNode.Kind == yaml.ScalarNode || len(valueNode.Content) == 0 {
+						valueNode.LineComment = sources[fieldKey]
+					} else {
+						keyNode.LineComment = sources[fieldKey]
+					}
+				}
+				node.Content = append(node.Content, keyNode, valueNode)
+			}
+			return node
+		case reflect.Slice:
+			node := &yaml.Node{Kind: yaml.SequenceNode}
+			if elem := value.Type().Elem(); value.Len() == 0 || (elem.Kind() != reflect.Struct && elem.Kind() != reflect.Pointer) {
+				node.Style = yaml.FlowStyle
+			}
+			for i := 0; i < value.Len(); i++ {
+				node.Content = append(node.Content, build(value.Index(i), key, true))
+			}
+			return node
+		case reflect.Pointer:
+			if value.IsNil() {
+				return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+			}
+			return build(value.Elem(), key, inList)
+		case reflect.String:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: redactURL(value.String())}
+		default:
+			node := &yaml.Node{}
+			// Scalars always encode
+			_ = node.Encode(value.Interface())
+			return node
+		}
+	}
+
+	return build(reflect.ValueOf(*c), "", false), sources
+}
+
+// redactURL redacts the credentials of URLs, other values are returned as
+// is. Tokens are often passed as the user name of HTTP URLs.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return u.Redacted()
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		u.User = url.User(redacted)
+		return u.String()
+	}
+	return value
+}
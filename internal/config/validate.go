@@ -0,0 +1,290 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError is a configuration error at a field path such as
+// catalog_mappings[1].targets[0].path.
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every FieldError of a configuration.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validator collects the errors of a configuration.
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errors = append(v.errors, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the configuration for values viper accepts but the proxy
+// can't work with, and returns a ValidationError listing all of them.
+// Patterns, version constraints and policy rules are checked by the
+// components parsing them.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		v.addf("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		v.addf("server.shutdown_timeout", "must be positive, got %s", c.Server.ShutdownTimeout)
+	}
+	if c.Server.ShutdownDelay < 0 {
+		v.addf("server.shutdown_delay", "must not be negative, got %s", c.Server.ShutdownDelay)
+	}
+
+	if c.Server.TLS.Enabled {
+		v.checkTLS(c.Server.TLS)
+	}
+
+	v.checkURL("artifacthub.base_url", c.ArtifactHub.BaseURL)
+	if c.ArtifactHub.Timeout <= 0 {
+		v.addf("artifacthub.timeout", "must be positive, got %s", c.ArtifactHub.Timeout)
+	}
+	if c.ArtifactHub.MaxRetries < 0 {
+		v.addf("artifacthub.max_retries", "must not be negative, got %d", c.ArtifactHub.MaxRetries)
+	}
+	if c.ArtifactHub.Cache.Enabled {
+		if c.ArtifactHub.Cache.TTL <= 0 {
+			v.addf("artifacthub.cache.ttl", "must be positive when the cache is enabled, got %s", c.ArtifactHub.Cache.TTL)
+		}
+		if c.ArtifactHub.Cache.MaxSize <= 0 {
+			v.addf("artifacthub.cache.max_size", "must be positive when the cache is enabled, got %d", c.ArtifactHub.Cache.MaxSize)
+		}
+	}
+
+	v.checkMappings(c.CatalogMappings)
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		v.addf("logging.level", "unknown level %q, expected one of panic, fatal, error, warn, info, debug, trace", c.Logging.Level)
+	}
+	if c.Logging.Format != "" && c.Logging.Format != "json" && c.Logging.Format != "text" {
+		v.addf("logging.format", "unknown format %q, expected json or text", c.Logging.Format)
+	}
+
+	if c.Mirror.Enabled && c.Mirror.Interval <= 0 {
+		v.addf("mirror.interval", "must be positive when the mirror is enabled, got %s", c.Mirror.Interval)
+	}
+	if c.Discovery.Enabled && c.Discovery.Interval <= 0 {
+		v.addf("discovery.interval", "must be positive when discovery is enabled, got %s", c.Discovery.Interval)
+	}
+
+	v.checkKinds(c.Kinds)
+	v.checkCategories(c.Categories)
+
+	for i, alias := range c.Aliases {
+		p := fmt.Sprintf("aliases[%d]", i)
+		if alias.Name == "" {
+			v.addf(p+".name", "is required")
+		}
+		if alias.To == (ResourceName{}) {
+			v.addf(p+".to", "must set a catalog, kind or name")
+		}
+	}
+
+	v.checkPolicy(c.Policy)
+
+	if len(v.errors) > 0 {
+		return &ValidationError{Errors: v.errors}
+	}
+	return nil
+}
+
+func (v *validator) checkURL(path, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addf(path, "must be an http or https URL, got %q", value)
+	}
+}
+
+func (v *validator) checkTLS(tls TLSConfig) {
+	if tls.CertFile == "" {
+		v.addf("server.tls.cert_file", "is required when TLS is enabled")
+	}
+	if tls.KeyFile == "" {
+		v.addf("server.tls.key_file", "is required when TLS is enabled")
+	}
+	switch tls.ClientAuth {
+	case "", ClientAuthRequire, ClientAuthVerifyIfGiven:
+	default:
+		v.addf("server.tls.client_auth", "unknown mode %q, expected %s or %s", tls.ClientAuth, ClientAuthRequire, ClientAuthVerifyIfGiven)
+	}
+	switch tls.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		v.addf("server.tls.min_version", "unsupported version %q, expected 1.2 or 1.3", tls.MinVersion)
+	}
+}
+
+func (v *validator) checkMappings(mappings []CatalogMapping) {
+	// Mappings are identified by catalog and kind, backends by target name
+	mappingPaths := make(map[string]string)
+	type servedTarget struct {
+		path   string
+		target CatalogTarget
+	}
+	targets := make(map[string]servedTarget)
+
+	for i, mapping := range mappings {
+		p := fmt.Sprintf("catalog_mappings[%d]", i)
+		if mapping.TektonHub == "" {
+			v.addf(p+".tekton_hub", "is required")
+		}
+		switch mapping.Pattern {
+		case "", PatternGlob, PatternRegex:
+		default:
+			v.addf(p+".pattern", "unknown pattern type %q, expected %s or %s", mapping.Pattern, PatternGlob, PatternRegex)
+		}
+
+		key := mapping.TektonHub + "/" + strings.ToLower(mapping.Kind)
+		if previous, exists := mappingPaths[key]; exists {
+			v.addf(p, "duplicate mapping of catalog %q, already mapped by %s", mapping.TektonHub, previous)
+		} else {
+			mappingPaths[key] = p
+		}
+
+		inline := mapping
+		inline.TektonHub, inline.Kind, inline.Pattern, inline.Targets, inline.Catalog = "", "", "", nil, CatalogMetadata{}
+		if len(mapping.Targets) > 0 && !reflect.DeepEqual(inline, CatalogMapping{}) {
+			v.addf(p, "set either targets or the target fields of the mapping, not both")
+		}
+
+		for j, target := range mapping.AllTargets() {
+			tp := p
+			if len(mapping.Targets) > 0 {
+				tp = fmt.Sprintf("%s.targets[%d]", p, j)
+			}
+			v.checkTarget(tp, target)
+
+			if mapping.Pattern != "" || (target.BackendName() == BackendArtifactHub && target.BaseURL == "") {
+				continue
+			}
+			if served, exists := targets[target.ArtifactHub]; exists && served.target != target {
+				v.addf(tp, "target %q is already served with other settings by %s", target.ArtifactHub, served.path)
+			} else if !exists {
+				targets[target.ArtifactHub] = servedTarget{path: tp, target: target}
+			}
+		}
+	}
+}
+
+func (v *validator) checkTarget(p string, target CatalogTarget) {
+	switch target.BackendName() {
+	case BackendArtifactHub:
+		if target.BaseURL != "" {
+			v.checkURL(p+".base_url", target.BaseURL)
+		}
+	case BackendFilesystem:
+		if target.Path == "" {
+			v.addf(p+".path", "is required by the filesystem backend")
+		}
+	case BackendGit:
+		if target.URL == "" {
+			v.addf(p+".url", "is required by the git backend")
+		}
+		if target.RefreshInterval < 0 {
+			v.addf(p+".refresh_interval", "must not be negative, got %s", target.RefreshInterval)
+		}
+		if target.Timeout < 0 {
+			v.addf(p+".timeout", "must not be negative, got %s", target.Timeout)
+		}
+	default:
+		v.addf(p+".backend", "unknown backend %q, expected %s, %s or %s", target.Backend, BackendArtifactHub, BackendFilesystem, BackendGit)
+	}
+}
+
+func (v *validator) checkKinds(kinds []KindMapping) {
+	names := make(map[string]bool)
+	repositoryKinds := make(map[int]bool)
+	for i, kind := range kinds {
+		p := fmt.Sprintf("kinds[%d]", i)
+		if kind.Name == "" {
+			v.addf(p+".name", "is required")
+		} else if names[strings.ToLower(kind.Name)] {
+			v.addf(p+".name", "duplicate kind %q", kind.Name)
+		}
+		names[strings.ToLower(kind.Name)] = true
+
+		if kind.RepositoryKind <= 0 {
+			v.addf(p+".repository_kind", "must be positive, got %d", kind.RepositoryKind)
+		} else if repositoryKinds[kind.RepositoryKind] {
+			v.addf(p+".repository_kind", "duplicate repository kind %d", kind.RepositoryKind)
+		}
+		repositoryKinds[kind.RepositoryKind] = true
+
+		if kind.RepoKind == "" {
+			v.addf(p+".repo_kind", "is required")
+		}
+	}
+}
+
+func (v *validator) checkCategories(categories []CategoryMapping) {
+	ids := make(map[int]bool)
+	names := make(map[string]bool)
+	for i, category := range categories {
+		p := fmt.Sprintf("categories[%d]", i)
+		if category.ID <= 0 {
+			v.addf(p+".id", "must be positive, got %d", category.ID)
+		} else if ids[category.ID] {
+			v.addf(p+".id", "duplicate category ID %d", category.ID)
+		}
+		ids[category.ID] = true
+
+		if category.Name == "" {
+			v.addf(p+".name", "is required")
+		} else if names[strings.ToLower(category.Name)] {
+			v.addf(p+".name", "duplicate category %q", category.Name)
+		}
+		names[strings.ToLower(category.Name)] = true
+	}
+}
+
+func (v *validator) checkPolicy(policy PolicyConfig) {
+	checkAction := func(p, action string) {
+		switch strings.ToLower(action) {
+		case PolicyAllow, PolicyDeny:
+		default:
+			v.addf(p, "unknown action %q, expected %s or %s", action, PolicyAllow, PolicyDeny)
+		}
+	}
+
+	if policy.Default != "" {
+		checkAction("policy.default", policy.Default)
+	}
+	for i, rule := range policy.Rules {
+		p := fmt.Sprintf("policy.rules[%d]", i)
+		checkAction(p+".action", rule.Action)
+		for j, name := range rule.Names {
+			if _, err := path.Match(name, ""); err != nil {
+				v.addf(fmt.Sprintf("%s.names[%d]", p, j), "invalid glob %q", name)
+			}
+		}
+	}
+}
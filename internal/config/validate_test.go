@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	return Config{
+		Server: ServerConfig{Port: 8080, Host: "0.0.0.0", ShutdownTimeout: 30 * time.Second},
+		ArtifactHub: ArtifactHubConfig{
+			BaseURL:    "https://artifacthub.io",
+			Timeout:    30 * time.Second,
+			MaxRetries: 3,
+			Cache:      CacheConfig{Enabled: true, TTL: time.Hour, MaxSize: 2000},
+		},
+		CatalogMappings: []CatalogMapping{
+			{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+			{TektonHub: "tekton", Kind: "pipeline", ArtifactHub: "tekton-catalog-pipelines"},
+			{TektonHub: "legacy", ArtifactHub: "tekton-catalog-tasks"},
+			{TektonHub: "local", Targets: []CatalogTarget{
+				{ArtifactHub: "local", Backend: BackendFilesystem, Path: "/catalog"},
+				{ArtifactHub: "upstream", Backend: BackendGit, URL: "https://github.com/tektoncd/catalog"},
+			}},
+		},
+		Logging:    LoggingConfig{Level: "info", Format: "json"},
+		Kinds:      DefaultKinds(),
+		Categories: DefaultCategories(),
+		Policy:     PolicyConfig{Default: PolicyAllow},
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Config)
+		expected []string
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{
+			name: "server and artifacthub",
+			modify: func(c *Config) {
+				c.Server.Port = 0
+				c.Server.ShutdownDelay = -time.Second
+				c.ArtifactHub.BaseURL = "artifacthub.io"
+				c.ArtifactHub.Timeout = -time.Second
+				c.ArtifactHub.Cache.MaxSize = 0
+			},
+			expected: []string{"server.port", "server.shutdown_delay", "artifacthub.base_url", "artifacthub.timeout", "artifacthub.cache.max_size"},
+		},
+		{
+			name: "tls",
+			modify: func(c *Config) {
+				c.Server.TLS = TLSConfig{Enabled: true, CertFile: "/tls/tls.crt", ClientAuth: "optional", MinVersion: "1.1"}
+			},
+			expected: []string{"server.tls.key_file", "server.tls.client_auth", "server.tls.min_version"},
+		},
+		{
+			name:   "disabled cache",
+			modify: func(c *Config) { c.ArtifactHub.Cache = CacheConfig{} },
+		},
+		{
+			name: "duplicate mapping",
+			modify: func(c *Config) {
+				c.CatalogMappings = append(c.CatalogMappings, CatalogMapping{TektonHub: "tekton", Kind: "Pipeline", ArtifactHub: "other"})
+			},
+			expected: []string{"catalog_mappings[4]"},
+		},
+		{
+			name: "conflicting target",
+			modify: func(c *Config) {
+				c.CatalogMappings = append(c.CatalogMappings, CatalogMapping{TektonHub: "mine", ArtifactHub: "local", Backend: BackendFilesystem, Path: "/other"})
+			},
+			expected: []string{"catalog_mappings[4]"},
+		},
+		{
+			name: "targets",
+			modify: func(c *Config) {
+				c.CatalogMappings[3].Path = "/catalog"
+				c.CatalogMappings[3].Targets[0].Path = ""
+				c.CatalogMappings[3].Targets[1].Backend = "svn"
+			},
+			expected: []string{"catalog_mappings[3]", "catalog_mappings[3].targets[0].path", "catalog_mappings[3].targets[1].backend"},
+		},
+		{
+			name: "logging, kinds, aliases and policy",
+			modify: func(c *Config) {
+				c.Logging.Level = "verbose"
+				c.Kinds = append(c.Kinds, KindMapping{Name: "Task", RepositoryKind: 7, RepoKind: "tekton-task"})
+				c.Aliases = []ResourceAlias{{Name: "git-clone"}}
+				c.Policy.Rules = []PolicyRule{{Action: "block", Names: []string{"[git"}}}
+			},
+			expected: []string{"logging.level", "kinds[3].name", "kinds[3].repository_kind", "aliases[0].to", "policy.rules[0].action", "policy.rules[0].names[0]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			var paths []string
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				for _, fieldErr := range validationErr.Errors {
+					paths = append(paths, fieldErr.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("expected errors at %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package discovery
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Status describes the state of the discovery loop.
+type Status struct {
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	Discovered  int       `json:"discovered"`
+	Overridden  int       `json:"overridden"`
+}
+
+// Report lists the mappings of the last discovery.
+type Report struct {
+	Status     Status                  `json:"status"`
+	Discovered []config.CatalogMapping `json:"discovered"`
+	// Overridden names the discovered repositories configured mappings
+	// already cover.
+	Overridden []string                `json:"overridden"`
+	Mappings   []config.CatalogMapping `json:"mappings"`
+}
+
+// Discoverer maps the Tekton repositories on Artifact Hub to catalogs of the
+// same name and merges them below the configured mappings. Every change is
+// handed to onChange, a failing discovery keeps the last mappings.
+type Discoverer struct {
+	source   client.RepositorySource
+	kinds    *translator.KindTable
+	cfg      config.DiscoveryConfig
+	onChange func([]config.CatalogMapping)
+
+	// mergeMutex orders the calls to onChange
+	mergeMutex   sync.Mutex
+	mutex        sync.RWMutex
+	configured   []config.CatalogMapping
+	status       Status
+	repositories []models.ArtifactHubRepository
+	discovered   []config.CatalogMapping
+	overridden   []string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewDiscoverer(source client.RepositorySource, kinds *translator.KindTable, cfg config.DiscoveryConfig, configured []config.CatalogMapping, onChange func([]config.CatalogMapping)) *Discoverer {
+	return &Discoverer{
+		source:     source,
+		kinds:      kinds,
+		cfg:        cfg,
+		configured: configured,
+		onChange:   onChange,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Start starts the discovery loop, the first discovery runs immediately.
+func (d *Discoverer) Start() {
+	go d.loop()
+}
+
+// Stop ends the discovery loop and waits for a running discovery to finish.
+func (d *Discoverer) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Discoverer) loop() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Discover(); err != nil {
+			logrus.WithError(err).Error("Catalog discovery failed, keeping previous mappings")
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Discover searches Artifact Hub and hands the merged mappings to onChange
+// when the discovered ones changed.
+func (d *Discoverer) Discover() error {
+	d.mutex.Lock()
+	d.status.LastAttempt = time.Now().UTC()
+	d.mutex.Unlock()
+
+	repositories, err := d.source.SearchRepositories(client.RepositorySearchParams{
+		Kinds:         d.kinds.RepositoryKinds(),
+		Organizations: d.cfg.Organizations,
+		Users:         d.cfg.Users,
+	})
+	if err != nil {
+		d.mutex.Lock()
+		d.status.LastError = err.Error()
+		d.mutex.Unlock()
+		return err
+	}
+
+	d.mutex.Lock()
+	d.repositories = repositories
+	d.status.LastSuccess = d.status.LastAttempt
+	d.status.LastError = ""
+	d.mutex.Unlock()
+
+	discovered, overridden, changed := d.merge(false)
+	logrus.WithFields(logrus.Fields{
+		"repositories": len(repositories),
+		"discovered":   discovered,
+		"overridden":   overridden,
+		"changed":      changed,
+	}).Info("🔭 Catalog discovery completed")
+	return nil
+}
+
+// SetConfigured replaces the configured mappings, as after a configuration
+// reload, and hands the mappings merged with the last discovered
+// repositories to onChange.
+func (d *Discoverer) SetConfigured(configured []config.CatalogMapping) {
+	d.mutex.Lock()
+	d.configured = configured
+	d.mutex.Unlock()
+
+	d.merge(true)
+}
+
+// merge maps the discovered repositories the configured mappings don't
+// cover and calls onChange when forced or the discovered mappings changed.
+// It returns the number of discovered and overridden repositories.
+func (d *Discoverer) merge(force bool) (int, int, bool) {
+	d.mergeMutex.Lock()
+	defer d.mergeMutex.Unlock()
+
+	d.mutex.RLock()
+	configuredMappings := d.configured
+	repositories := d.repositories
+	d.mutex.RUnlock()
+
+	// Configured mappings win, whether they name the catalog or already
+	// serve the repository
+	configured := translator.NewCatalogTranslator(configuredMappings, d.kinds)
+	names := make(map[string]bool)
+	var discovered []config.CatalogMapping
+	var overridden []string
+	for _, repository := range repositories {
+		if names[repository.Name] ||
+			(d.cfg.VerifiedOnly && !repository.VerifiedPublisher) ||
+			(d.cfg.OfficialOnly && !repository.Official) {
+			continue
+		}
+		names[repository.Name] = true
+
+		if configured.CheckCatalog(repository.Name) == nil || configured.IsMapped(repository.Name) {
+			overridden = append(overridden, repository.Name)
+			continue
+		}
+		discovered = append(discovered, config.CatalogMapping{
+			TektonHub:   repository.Name,
+			ArtifactHub: repository.Name,
+		})
+	}
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].TektonHub < discovered[j].TektonHub })
+	sort.Strings(overridden)
+
+	d.mutex.Lock()
+	changed := !reflect.DeepEqual(discovered, d.discovered)
+	d.discovered = discovered
+	d.overridden = overridden
+	d.status.Discovered = len(discovered)
+	d.status.Overridden = len(overridden)
+	d.mutex.Unlock()
+
+	if changed || force {
+		d.onChange(d.Mappings())
+	}
+	return len(discovered), len(overridden), changed
+}
+
+// Mappings returns the configured mappings followed by the discovered ones.
+func (d *Discoverer) Mappings() []config.CatalogMapping {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	mappings := make([]config.CatalogMapping, 0, len(d.configured)+len(d.discovered))
+	mappings = append(mappings, d.configured...)
+	return append(mappings, d.discovered...)
+}
+
+// DiscoveryReport returns the Report of the last discovery.
+func (d *Discoverer) DiscoveryReport() any {
+	mappings := d.Mappings()
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return Report{
+		Status:     d.status,
+		Discovered: append([]config.CatalogMapping{}, d.discovered...),
+		Overridden: append([]string{}, d.overridden...),
+		Mappings:   mappings,
+	}
+}
+
+// HealthStatus reports the discovery status. Configured mappings keep
+// working while discovery fails, so it is always healthy.
+func (d *Discoverer) HealthStatus() (any, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.status, true
+}
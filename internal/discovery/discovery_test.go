@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"errors"
+	"reflect"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	repositories []models.ArtifactHubRepository
+	params       client.RepositorySearchParams
+	err          error
+}
+
+func (f *fakeSource) GetRepository(name string) (*models.ArtifactHubRepository, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeSource) SearchRepositories(params client.RepositorySearchParams) ([]models.ArtifactHubRepository, error) {
+	f.params = params
+	return f.repositories, f.err
+}
+
+func TestDiscoverer_Discover(t *testing.T) {
+	source := &fakeSource{repositories: []models.ArtifactHubRepository{
+		{Name: "tekton-catalog-tasks", Kind: 7, Official: true, VerifiedPublisher: true},
+		{Name: "acme-tasks", Kind: 7, VerifiedPublisher: true},
+		{Name: "acme-pipelines", Kind: 11, VerifiedPublisher: true},
+		{Name: "team-ci", Kind: 7, VerifiedPublisher: true},
+		{Name: "unverified", Kind: 7},
+	}}
+	configured := []config.CatalogMapping{
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "team-*", Pattern: config.PatternGlob, ArtifactHub: "team-*"},
+	}
+
+	var changes [][]config.CatalogMapping
+	discoverer := NewDiscoverer(source, translator.DefaultKindTable(), config.DiscoveryConfig{
+		Interval:      time.Hour,
+		Organizations: []string{"acme"},
+		VerifiedOnly:  true,
+	}, configured, func(mappings []config.CatalogMapping) {
+		changes = append(changes, mappings)
+	})
+
+	if err := discoverer.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(source.params.Kinds, []int{7, 11, 23}) || !reflect.DeepEqual(source.params.Organizations, []string{"acme"}) {
+		t.Errorf("unexpected search params %+v", source.params)
+	}
+
+	expected := append(append([]config.CatalogMapping{}, configured...),
+		config.CatalogMapping{TektonHub: "acme-pipelines", ArtifactHub: "acme-pipelines"},
+		config.CatalogMapping{TektonHub: "acme-tasks", ArtifactHub: "acme-tasks"},
+	)
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0], expected) {
+		t.Fatalf("expected one change to %+v, got %+v", expected, changes)
+	}
+
+	report := discoverer.DiscoveryReport().(Report)
+	if !reflect.DeepEqual(report.Overridden, []string{"team-ci", "tekton-catalog-tasks"}) {
+		t.Errorf("unexpected overridden repositories %v", report.Overridden)
+	}
+
+	// Unchanged results and failures keep the mappings
+	if err := discoverer.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source.err = errors.New("unavailable")
+	if err := discoverer.Discover(); err == nil {
+		t.Error("expected error")
+	}
+	if len(changes) != 1 {
+		t.Errorf("expected no further changes, got %d", len(changes))
+	}
+	if status := discoverer.DiscoveryReport().(Report).Status; status.LastError == "" || status.Discovered != 2 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestDiscoverer_SetConfigured(t *testing.T) {
+	source := &fakeSource{repositories: []models.ArtifactHubRepository{
+		{Name: "acme-tasks", Kind: 7},
+		{Name: "acme-pipelines", Kind: 11},
+	}}
+
+	var changes [][]config.CatalogMapping
+	discoverer := NewDiscoverer(source, translator.DefaultKindTable(), config.DiscoveryConfig{Interval: time.Hour}, nil, func(mappings []config.CatalogMapping) {
+		changes = append(changes, mappings)
+	})
+	if err := discoverer.Discover(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reloaded mappings apply to the last discovered repositories without
+	// searching again
+	source.err = errors.New("unavailable")
+	discoverer.SetConfigured([]config.CatalogMapping{{TektonHub: "acme", ArtifactHub: "acme-tasks"}})
+
+	expected := []config.CatalogMapping{
+		{TektonHub: "acme", ArtifactHub: "acme-tasks"},
+		{TektonHub: "acme-pipelines", ArtifactHub: "acme-pipelines"},
+	}
+	if len(changes) != 2 || !reflect.DeepEqual(changes[1], expected) {
+		t.Fatalf("expected a change to %+v, got %+v", expected, changes)
+	}
+}
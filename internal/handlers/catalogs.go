@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultCatalogsTTL is how long catalog metadata is kept when the Artifact
+// Hub cache has no TTL.
+const defaultCatalogsTTL = time.Hour
+
+// partialCatalogsTTL is how long lists missing the repository of a catalog
+// are kept, so an unreachable Artifact Hub isn't asked on every call.
+const partialCatalogsTTL = 30 * time.Second
+
+// catalogs returns the mapped catalogs in configuration order, described by
+// the repository of their first target. Repositories are fetched without
+// holding the lock, lists missing some of them are cached shortly.
+func (v *view) catalogs() []models.TektonHubCatalog {
+	ttl := v.config().ArtifactHub.Cache.TTL
+	if ttl <= 0 {
+		ttl = defaultCatalogsTTL
+	}
+
+	v.catalogsMutex.Lock()
+	if v.catalogList != nil && time.Now().Before(v.catalogsExpiry) {
+		defer v.catalogsMutex.Unlock()
+		return v.catalogList
+	}
+	generation := v.catalogsGeneration
+	v.catalogsMutex.Unlock()
+
+	catalogs, complete := v.fetchCatalogs()
+	if !complete {
+		ttl = min(ttl, partialCatalogsTTL)
+	}
+
+	v.catalogsMutex.Lock()
+	defer v.catalogsMutex.Unlock()
+	// Lists fetched with the settings of before a reload are dropped
+	if generation == v.catalogsGeneration {
+		v.catalogList = catalogs
+		v.catalogsExpiry = time.Now().Add(ttl)
+	}
+	return catalogs
+}
+
+// fetchCatalogs describes the mapped catalogs, complete is false when the
+// repository of a catalog couldn't be fetched.
+func (v *view) fetchCatalogs() (catalogs []models.TektonHubCatalog, complete bool) {
+	complete = true
+	catalogs = []models.TektonHubCatalog{}
+	seen := make(map[string]bool)
+	for _, mapping := range v.catalogTranslator().Mappings() {
+		// Catalogs matched by patterns can't be listed, kind scoped mappings
+		// of one catalog are listed once
+		if mapping.Pattern != "" || seen[mapping.TektonHub] {
+			continue
+		}
+		seen[mapping.TektonHub] = true
+		target := mapping.AllTargets()[0].ArtifactHub
+
+		var repository *models.ArtifactHubRepository
+		if b, ok := v.backends().For(target).(backend.RepositoryBackend); ok {
+			found, err := b.Repository(target)
+			if err != nil {
+				logrus.WithError(err).WithField("catalog", target).Warn("⚠️  Failed to get catalog repository, using configured metadata")
+				complete = false
+			} else {
+				repository = found
+			}
+		}
+
+		catalogs = append(catalogs, v.catalogTranslator().Catalog(mapping.TektonHub, repository))
+	}
+	return catalogs, complete
+}
+
+// resetCatalogs drops the cached catalogs, and the lists being fetched,
+// after the settings changed.
+func (h *Handlers) resetCatalogs() {
+	h.catalogsMutex.Lock()
+	defer h.catalogsMutex.Unlock()
+	h.catalogList = nil
+	h.catalogsGeneration++
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+)
+
+// repositoryBackend describes its catalog, failing while err is set.
+type repositoryBackend struct {
+	*fakeBackend
+	err     error
+	fetches int
+}
+
+func (b *repositoryBackend) Repository(catalog string) (*models.ArtifactHubRepository, error) {
+	b.fetches++
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &models.ArtifactHubRepository{Name: catalog, DisplayName: "Tekton"}, nil
+}
+
+func TestHandlers_Catalogs_Cache(t *testing.T) {
+	b := &repositoryBackend{fakeBackend: newFakeBackend("tekton-catalog-tasks"), err: errors.New("unavailable")}
+	cfg := &config.Config{CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}}
+	h := newTestHandlers(t, cfg, b, nil)
+
+	if catalogs := h.view().catalogs(); len(catalogs) != 1 || catalogs[0].DisplayName != "" {
+		t.Fatalf("expected the configured metadata, got %+v", catalogs)
+	}
+	h.view().catalogs()
+	if b.fetches != 1 {
+		t.Errorf("expected the partial list to be cached, got %d fetches", b.fetches)
+	}
+	if ttl := time.Until(h.catalogsExpiry); ttl > partialCatalogsTTL {
+		t.Errorf("expected the partial list to expire within %s, got %s", partialCatalogsTTL, ttl)
+	}
+
+	b.err = nil
+	h.resetCatalogs()
+	if catalogs := h.view().catalogs(); catalogs[0].DisplayName != "Tekton" {
+		t.Errorf("expected the repository metadata after a reset, got %+v", catalogs)
+	}
+	if ttl := time.Until(h.catalogsExpiry); ttl <= partialCatalogsTTL {
+		t.Errorf("expected the complete list to be kept for the cache TTL, got %s", ttl)
+	}
+}
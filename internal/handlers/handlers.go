@@ -4,46 +4,230 @@ import (
 	"encoding/json"
 	"html/template"
 	"net/http"
-	"tekton-hub-proxy/internal/client"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"tekton-hub-proxy/internal/backend"
 	"tekton-hub-proxy/internal/config"
 	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/policy"
 	"tekton-hub-proxy/internal/translator"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 type Handlers struct {
-	artifactHubClient  *client.ArtifactHubClient
-	catalogTranslator  *translator.CatalogTranslator
+	settings           atomic.Pointer[settings]
+	settingsMutex      sync.Mutex
 	responseTranslator *translator.ResponseTranslator
 	versionTranslator  *translator.VersionTranslator
-	config             *config.Config
+	healthReporters    map[string]HealthReporter
+	readinessChecks    map[string]ReadinessCheck
+	discovery          DiscoveryReporter
+	shuttingDown       atomic.Bool
+
+	resourceIDsMutex   sync.RWMutex
+	resourceIDs        map[int]resourceRef
+	resourceIDsIndexed time.Time
+
+	catalogsMutex      sync.Mutex
+	catalogList        []models.TektonHubCatalog
+	catalogsExpiry     time.Time
+	catalogsGeneration int
+}
+
+// settings are the parts of the handlers a configuration reload or catalog
+// discovery replaces, swapped as a whole.
+type settings struct {
+	config            *config.Config
+	backends          *backend.Registry
+	catalogTranslator *translator.CatalogTranslator
+	aliases           *translator.AliasTable
+	policy            *policy.Engine
+}
+
+// view serves one request with the settings current when it started, so a
+// reload in the middle of a request can't mix two configurations.
+type view struct {
+	*Handlers
+	current *settings
+}
+
+// DiscoveryReporter reports the catalog mappings found by discovery.
+type DiscoveryReporter interface {
+	DiscoveryReport() any
+}
+
+// HealthReporter contributes a named section to the /health response.
+type HealthReporter interface {
+	HealthStatus() (status any, healthy bool)
+}
+
+// ReadinessCheck holds /ready back until the component can serve, such as
+// a mirror waiting for its first sync.
+type ReadinessCheck interface {
+	Ready() bool
 }
 
 func NewHandlers(
-	artifactHubClient *client.ArtifactHubClient,
+	backends *backend.Registry,
 	catalogTranslator *translator.CatalogTranslator,
 	responseTranslator *translator.ResponseTranslator,
 	versionTranslator *translator.VersionTranslator,
+	aliases *translator.AliasTable,
+	policy *policy.Engine,
 	config *config.Config,
 ) *Handlers {
-	return &Handlers{
-		artifactHubClient:  artifactHubClient,
-		catalogTranslator:  catalogTranslator,
+	h := &Handlers{
 		responseTranslator: responseTranslator,
 		versionTranslator:  versionTranslator,
-		config:             config,
+		healthReporters:    make(map[string]HealthReporter),
+		readinessChecks:    make(map[string]ReadinessCheck),
+		resourceIDs:        make(map[int]resourceRef),
+	}
+	h.settings.Store(&settings{config: config, backends: backends, catalogTranslator: catalogTranslator, aliases: aliases, policy: policy})
+	return h
+}
+
+// view loads the current settings for a request.
+func (h *Handlers) view() *view {
+	return &view{Handlers: h, current: h.settings.Load()}
+}
+
+func (v *view) config() *config.Config {
+	return v.current.config
+}
+
+func (v *view) backends() *backend.Registry {
+	return v.current.backends
+}
+
+// catalogTranslator returns the catalog translator of the current mappings.
+func (v *view) catalogTranslator() *translator.CatalogTranslator {
+	return v.current.catalogTranslator
+}
+
+func (v *view) aliases() *translator.AliasTable {
+	return v.current.aliases
+}
+
+func (v *view) policy() *policy.Engine {
+	return v.current.policy
+}
+
+// Reconfigure swaps in the settings of a reloaded configuration, requests
+// in flight finish with the previous ones. A nil catalog translator keeps
+// the current one, such as when discovery merges the changed mappings.
+func (h *Handlers) Reconfigure(config *config.Config, catalogTranslator *translator.CatalogTranslator, backends *backend.Registry, aliases *translator.AliasTable, policy *policy.Engine) {
+	h.updateSettings(func(s *settings) {
+		s.config, s.backends, s.aliases, s.policy = config, backends, aliases, policy
+		if catalogTranslator != nil {
+			s.catalogTranslator = catalogTranslator
+		}
+	})
+}
+
+// SetCatalogTranslator swaps in the translator of changed mappings, requests
+// in flight finish with the previous one.
+func (h *Handlers) SetCatalogTranslator(catalogTranslator *translator.CatalogTranslator) {
+	h.updateSettings(func(s *settings) {
+		s.catalogTranslator = catalogTranslator
+	})
+}
+
+// updateSettings swaps in a copy of the settings changed by update.
+func (h *Handlers) updateSettings(update func(*settings)) {
+	h.settingsMutex.Lock()
+	defer h.settingsMutex.Unlock()
+
+	next := *h.settings.Load()
+	update(&next)
+	h.settings.Store(&next)
+	h.resetCatalogs()
+	h.resetResourceIDs()
+}
+
+// AddHealthReporter includes the reporter's status in /health under name.
+// Must be called before the server starts.
+func (h *Handlers) AddHealthReporter(name string, reporter HealthReporter) {
+	h.healthReporters[name] = reporter
+}
+
+// AddReadinessCheck fails /ready under name until check is ready. Must be
+// called before the server starts.
+func (h *Handlers) AddReadinessCheck(name string, check ReadinessCheck) {
+	h.readinessChecks[name] = check
+}
+
+// SetDiscovery serves the reporter's mappings on the discovery admin
+// endpoint. Must be called before the server starts.
+func (h *Handlers) SetDiscovery(reporter DiscoveryReporter) {
+	h.discovery = reporter
+}
+
+// GetDiscoveredMappings lists the configured and discovered catalog mappings.
+func (h *Handlers) GetDiscoveredMappings(w http.ResponseWriter, r *http.Request) {
+	if h.discovery == nil {
+		h.writeErrorResponse(w, http.StatusNotFound, "catalog discovery is not enabled")
+		return
+	}
+	h.writeJSONResponse(w, http.StatusOK, h.discovery.DiscoveryReport())
+}
+
+// GetEffectiveConfig shows the configuration the proxy runs with, secrets
+// redacted, and the source of each setting.
+func (h *Handlers) GetEffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
+	h.writeJSONResponse(w, http.StatusOK, v.config().Effective())
+}
+
+// SetShuttingDown fails readiness so load balancers stop sending requests
+// while the ones in flight drain.
+func (h *Handlers) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Readiness reports whether the proxy takes new requests: it isn't shutting
+// down and every readiness check passes.
+func (h *Handlers) Readiness(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		h.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]any{"status": "shutting_down"})
+		return
+	}
+	var waiting []string
+	for name, check := range h.readinessChecks {
+		if !check.Ready() {
+			waiting = append(waiting, name)
+		}
+	}
+	if len(waiting) > 0 {
+		sort.Strings(waiting)
+		h.writeJSONResponse(w, http.StatusServiceUnavailable, map[string]any{"status": "not_ready", "waiting_for": waiting})
+		return
 	}
+	h.writeJSONResponse(w, http.StatusOK, map[string]any{"status": "ready"})
 }
 
 func (h *Handlers) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
+	response := map[string]any{"status": "healthy"}
+	statusCode := http.StatusOK
+
+	for name, reporter := range h.healthReporters {
+		status, healthy := reporter.HealthStatus()
+		response[name] = status
+		if !healthy {
+			response["status"] = "unhealthy"
+			statusCode = http.StatusServiceUnavailable
+		}
+	}
+
+	h.writeJSONResponse(w, statusCode, response)
 }
 
 func (h *Handlers) LandingPage(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	tmpl := `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -327,6 +511,7 @@ func (h *Handlers) LandingPage(w http.ResponseWriter, r *http.Request) {
         <div class="api-endpoints">
             <h3>Available API Endpoints</h3>
             <div class="endpoint"><span class="method">GET</span>/v1/catalogs</div>
+            <div class="endpoint"><span class="method">GET</span>/v1/categories</div>
             <div class="endpoint"><span class="method">GET</span>/v1/resources</div>
             <div class="endpoint"><span class="method">GET</span>/v1/resource/{catalog}/{kind}/{name}</div>
             <div class="endpoint"><span class="method">GET</span>/v1/resource/{catalog}/{kind}/{name}/{version}</div>
@@ -428,7 +613,7 @@ hub-catalog-type: tektonhub</pre>
 	data := struct {
 		CacheTTL string
 	}{
-		CacheTTL: h.config.ArtifactHub.Cache.TTL.String(),
+		CacheTTL: v.config().ArtifactHub.Cache.TTL.String(),
 	}
 
 	if err := t.Execute(w, data); err != nil {
@@ -438,32 +623,28 @@ hub-catalog-type: tektonhub</pre>
 }
 
 func (h *Handlers) ListCatalogs(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	logrus.Debug("Listing catalogs")
 
-	// Create mock catalogs based on our mappings
-	var catalogs []models.TektonHubCatalog
-	mappings := h.catalogTranslator.GetAvailableMappings()
-
-	id := 1
-	for tektonCatalog := range mappings {
-		catalogs = append(catalogs, models.TektonHubCatalog{
-			ID:       id,
-			Name:     tektonCatalog,
-			Provider: "github",
-			Type:     "community",
-			URL:      "https://github.com/tektoncd/catalog",
-		})
-		id++
+	response := models.TektonHubCatalogResponse{
+		Data: v.catalogs(),
 	}
 
-	response := models.TektonHubCatalogResponse{
-		Data: catalogs,
+	h.writeJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handlers) ListCategories(w http.ResponseWriter, r *http.Request) {
+	logrus.Debug("Listing categories")
+
+	response := models.TektonHubCategoriesResponse{
+		Data: h.responseTranslator.Categories().Categories(),
 	}
 
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
 func (h *Handlers) GetResource(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -475,46 +656,30 @@ func (h *Handlers) GetResource(w http.ResponseWriter, r *http.Request) {
 		"name":    name,
 	}).Debug("Getting resource")
 
-	// Convert catalog name
-	artifactHubCatalog, err := h.catalogTranslator.TektonToArtifactHub(catalog)
-	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid catalog name")
-		return
-	}
-
-	// Convert kind to repo kind
-	repoKind := h.catalogTranslator.KindToRepoKind(kind)
-
-	// Log the translation for debugging
-	logrus.WithFields(logrus.Fields{
-		"original_catalog":     catalog,
-		"translated_catalog":   artifactHubCatalog,
-		"original_kind":        kind,
-		"translated_repo_kind": repoKind,
-		"name":                 name,
-	}).Info("🔍 Translation details")
-
-	// Get latest package from Artifact Hub
-	pkg, err := h.artifactHubClient.GetPackageLatest(repoKind, artifactHubCatalog, name)
+	// Get the latest, or ?version=, package from the first target serving it
+	pkg, err := v.getPackage(w, r, catalog, kind, name, r.URL.Query().Get("version"))
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
-			"repo_kind": repoKind,
-			"catalog":   artifactHubCatalog,
-			"name":      name,
-			"error":     err.Error(),
-		}).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+			"catalog": catalog,
+			"kind":    kind,
+			"name":    name,
+			"error":   err.Error(),
+		}).Error("Failed to get package")
+		h.writePackageError(w, err, "resource not found")
 		return
 	}
 
 	// Convert to Tekton Hub format
-	resource, err := h.responseTranslator.ArtifactHubPackageToTektonResource(pkg, h.catalogTranslator)
+	resource, err := h.responseTranslator.ArtifactHubPackageToTektonResource(pkg, v.catalogTranslator())
 	if err != nil {
 		logrus.WithError(err).Error("Failed to convert package to resource")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
 		return
 	}
 
+	h.rememberResources(*resource)
+	v.hideVersions(pkg, resource)
+
 	response := models.TektonHubResourceResponse{
 		Data: *resource,
 	}
@@ -523,6 +688,7 @@ func (h *Handlers) GetResource(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetResourceVersion(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -536,43 +702,28 @@ func (h *Handlers) GetResourceVersion(w http.ResponseWriter, r *http.Request) {
 		"version": version,
 	}).Debug("Getting resource version")
 
-	// Convert catalog name
-	artifactHubCatalog, err := h.catalogTranslator.TektonToArtifactHub(catalog)
-	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid catalog name")
-		return
-	}
-
-	// Convert version
-	artifactHubVersion, err := h.versionTranslator.TektonToArtifactHub(version)
+	// Get package from the first target serving it
+	pkg, err := v.getPackage(w, r, catalog, kind, name, version)
 	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid version format")
-		return
-	}
-
-	// Convert kind to repo kind
-	repoKind := h.catalogTranslator.KindToRepoKind(kind)
-
-	// Get package from Artifact Hub
-	pkg, err := h.artifactHubClient.GetPackage(repoKind, artifactHubCatalog, name, artifactHubVersion)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource version not found")
+		logrus.WithError(err).Error("Failed to get package")
+		h.writePackageError(w, err, "resource version not found")
 		return
 	}
 
 	// Convert to Tekton Hub format
-	resource, err := h.responseTranslator.ArtifactHubPackageToTektonResource(pkg, h.catalogTranslator)
+	resource, err := h.responseTranslator.ArtifactHubPackageToTektonResource(pkg, v.catalogTranslator())
 	if err != nil {
 		logrus.WithError(err).Error("Failed to convert package to resource")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
 		return
 	}
 
+	v.hideVersions(pkg, resource)
+
 	// Return the latest version details as resource version
 	response := models.TektonHubResourceVersion{
 		ID:                  resource.LatestVersion.ID,
-		Version:             version, // Use original Tekton version
+		Version:             resource.LatestVersion.Version,
 		DisplayName:         resource.LatestVersion.DisplayName,
 		Description:         resource.LatestVersion.Description,
 		MinPipelinesVersion: resource.LatestVersion.MinPipelinesVersion,
@@ -584,12 +735,14 @@ func (h *Handlers) GetResourceVersion(w http.ResponseWriter, r *http.Request) {
 		HubRawURLPath:       resource.LatestVersion.HubRawURLPath,
 		Resource:            resource,
 		Deprecated:          resource.LatestVersion.Deprecated,
+		Prerelease:          resource.LatestVersion.Prerelease,
 	}
 
 	h.writeJSONResponse(w, http.StatusOK, map[string]interface{}{"data": response})
 }
 
 func (h *Handlers) GetResourceYAML(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -603,10 +756,10 @@ func (h *Handlers) GetResourceYAML(w http.ResponseWriter, r *http.Request) {
 		"version": version,
 	}).Debug("Getting resource YAML")
 
-	pkg, err := h.getPackageFromArtifactHub(catalog, kind, name, version)
+	pkg, err := v.getPackage(w, r, catalog, kind, name, version)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+		logrus.WithError(err).Error("Failed to get package")
+		h.writePackageError(w, err, "resource not found")
 		return
 	}
 
@@ -622,6 +775,7 @@ func (h *Handlers) GetResourceYAML(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetResourceYAMLRaw(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -635,10 +789,10 @@ func (h *Handlers) GetResourceYAMLRaw(w http.ResponseWriter, r *http.Request) {
 		"version": version,
 	}).Debug("Getting raw resource YAML")
 
-	pkg, err := h.getPackageFromArtifactHub(catalog, kind, name, version)
+	pkg, err := v.getPackage(w, r, catalog, kind, name, version)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+		logrus.WithError(err).Error("Failed to get package")
+		h.writePackageError(w, err, "resource not found")
 		return
 	}
 
@@ -649,6 +803,7 @@ func (h *Handlers) GetResourceYAMLRaw(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handlers) GetLatestResourceYAML(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -660,10 +815,10 @@ func (h *Handlers) GetLatestResourceYAML(w http.ResponseWriter, r *http.Request)
 		"name":    name,
 	}).Debug("Getting latest resource YAML")
 
-	pkg, err := h.getPackageFromArtifactHub(catalog, kind, name, "")
+	pkg, err := v.getPackage(w, r, catalog, kind, name, r.URL.Query().Get("version"))
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+		logrus.WithError(err).Error("Failed to get package")
+		h.writePackageError(w, err, "resource not found")
 		return
 	}
 
@@ -674,6 +829,7 @@ func (h *Handlers) GetLatestResourceYAML(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *Handlers) GetResourceReadme(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	vars := mux.Vars(r)
 	catalog := vars["catalog"]
 	kind := vars["kind"]
@@ -687,10 +843,10 @@ func (h *Handlers) GetResourceReadme(w http.ResponseWriter, r *http.Request) {
 		"version": version,
 	}).Debug("Getting resource README")
 
-	pkg, err := h.getPackageFromArtifactHub(catalog, kind, name, version)
+	pkg, err := v.getPackage(w, r, catalog, kind, name, version)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get package from Artifact Hub")
-		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+		logrus.WithError(err).Error("Failed to get package")
+		h.writePackageError(w, err, "resource not found")
 		return
 	}
 
@@ -705,30 +861,6 @@ func (h *Handlers) GetResourceReadme(w http.ResponseWriter, r *http.Request) {
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
-func (h *Handlers) getPackageFromArtifactHub(catalog, kind, name, version string) (*models.ArtifactHubPackage, error) {
-	// Convert catalog name
-	artifactHubCatalog, err := h.catalogTranslator.TektonToArtifactHub(catalog)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert kind to repo kind
-	repoKind := h.catalogTranslator.KindToRepoKind(kind)
-
-	if version == "" {
-		// Get latest version
-		return h.artifactHubClient.GetPackageLatest(repoKind, artifactHubCatalog, name)
-	}
-
-	// Convert version
-	artifactHubVersion, err := h.versionTranslator.TektonToArtifactHub(version)
-	if err != nil {
-		return nil, err
-	}
-
-	return h.artifactHubClient.GetPackage(repoKind, artifactHubCatalog, name, artifactHubVersion)
-}
-
 func (h *Handlers) writeJSONResponse(w http.ResponseWriter, statusCode int, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
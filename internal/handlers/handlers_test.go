@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/translator"
+)
+
+// newTestHandlers builds handlers for cfg. Targets in backends are served
+// by their backend, the others by defaultBackend.
+func newTestHandlers(t *testing.T, cfg *config.Config, defaultBackend backend.Backend, backends map[string]backend.Backend) *Handlers {
+	t.Helper()
+	if len(cfg.Kinds) == 0 {
+		cfg.Kinds = config.DefaultKinds()
+	}
+	if cfg.Policy.Default == "" {
+		cfg.Policy.Default = config.PolicyAllow
+	}
+
+	kinds := translator.NewKindTable(cfg.Kinds)
+	versionTranslator := translator.NewVersionTranslator()
+	aliases, err := translator.NewAliasTable(cfg.Aliases, versionTranslator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine, err := policy.NewEngine(cfg.Policy, versionTranslator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := backend.NewRegistry(defaultBackend)
+	for target, b := range backends {
+		registry.Register(target, b)
+	}
+
+	return NewHandlers(
+		registry,
+		translator.NewCatalogTranslator(cfg.CatalogMappings, kinds),
+		translator.NewResponseTranslator(kinds, versionTranslator, translator.NewCategoryTable(config.DefaultCategories())),
+		versionTranslator,
+		aliases,
+		engine,
+		cfg,
+	)
+}
+
+// fakeBackend serves the versions of packages from memory, the first version
+// listed for a package is its upstream latest. It counts the packages
+// fetched and the searches.
+type fakeBackend struct {
+	catalog  string
+	packages map[string][]models.ArtifactHubPackage
+	fetches  atomic.Int32
+	searches atomic.Int32
+}
+
+func newFakeBackend(catalog string, versions ...models.ArtifactHubPackage) *fakeBackend {
+	b := &fakeBackend{catalog: catalog, packages: make(map[string][]models.ArtifactHubPackage)}
+	for _, version := range versions {
+		version.Repository = models.ArtifactHubRepository{Name: catalog, Kind: 7}
+		b.packages[version.Name] = append(b.packages[version.Name], version)
+	}
+	return b
+}
+
+func (b *fakeBackend) GetLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	versions, err := b.ListVersions(repoKind, catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	return b.GetVersion(repoKind, catalog, name, versions[0].Version)
+}
+
+func (b *fakeBackend) GetVersion(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	b.fetches.Add(1)
+	available, _ := b.ListVersions(repoKind, catalog, name)
+	for _, pkg := range b.packages[name] {
+		if pkg.Version == version {
+			pkg.AvailableVersions = available
+			return &pkg, nil
+		}
+	}
+	return nil, client.ErrNotFound
+}
+
+func (b *fakeBackend) ListVersions(repoKind, catalog, name string) ([]models.ArtifactHubVersion, error) {
+	var versions []models.ArtifactHubVersion
+	for _, pkg := range b.packages[name] {
+		versions = append(versions, models.ArtifactHubVersion{Version: pkg.Version, Prerelease: pkg.Prerelease})
+	}
+	if len(versions) == 0 {
+		return nil, client.ErrNotFound
+	}
+	return versions, nil
+}
+
+func (b *fakeBackend) Search(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	b.searches.Add(1)
+	search := &models.ArtifactHubSearchResponse{}
+	for name, versions := range b.packages {
+		search.Packages = append(search.Packages, models.ArtifactHubPackageSummary{
+			PackageID:  versions[0].PackageID,
+			Name:       name,
+			Version:    versions[0].Version,
+			Repository: versions[0].Repository,
+		})
+	}
+	sort.Slice(search.Packages, func(i, j int) bool { return search.Packages[i].Name < search.Packages[j].Name })
+	if params.Limit > 0 && len(search.Packages) > params.Limit {
+		search.Packages = search.Packages[:params.Limit]
+	}
+	return search, nil
+}
+
+// serve calls handler for a request of path with the route variables vars.
+func serve(handler http.HandlerFunc, path string, vars map[string]string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler(recorder, mux.SetURLVars(httptest.NewRequest(http.MethodGet, path, nil), vars))
+	return recorder
+}
+
+type readyCheck struct {
+	ready bool
+}
+
+func (c *readyCheck) Ready() bool {
+	return c.ready
+}
+
+func TestHandlers_Readiness(t *testing.T) {
+	h := NewHandlers(nil, nil, nil, nil, nil, nil, nil)
+
+	if code := serve(h.Readiness, "/ready", nil).Code; code != http.StatusOK {
+		t.Errorf("expected %d before shutdown, got %d", http.StatusOK, code)
+	}
+	waiting := &readyCheck{}
+	h.AddReadinessCheck("mirror", waiting)
+	if code := serve(h.Readiness, "/ready", nil).Code; code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d while a check isn't ready, got %d", http.StatusServiceUnavailable, code)
+	}
+	waiting.ready = true
+	if code := serve(h.Readiness, "/ready", nil).Code; code != http.StatusOK {
+		t.Errorf("expected %d once every check is ready, got %d", http.StatusOK, code)
+	}
+
+	h.SetShuttingDown()
+	if code := serve(h.Readiness, "/ready", nil).Code; code != http.StatusServiceUnavailable {
+		t.Errorf("expected %d while shutting down, got %d", http.StatusServiceUnavailable, code)
+	}
+}
+
+func TestHandlers_Reconfigure(t *testing.T) {
+	h := newTestHandlers(t, &config.Config{}, newFakeBackend("tekton"), nil)
+	before := h.view()
+	mappings := []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}
+	mapped := translator.NewCatalogTranslator(mappings, translator.NewKindTable(config.DefaultKinds()))
+
+	h.Reconfigure(&config.Config{CatalogMappings: mappings}, mapped, before.backends(), before.aliases(), before.policy())
+	after := h.view()
+	if after.catalogTranslator() != mapped || len(after.config().CatalogMappings) != 1 {
+		t.Error("expected the translator to be swapped with the config")
+	}
+	if before.catalogTranslator() == mapped || len(before.config().CatalogMappings) != 0 {
+		t.Error("expected a request in flight to keep the settings it started with")
+	}
+
+	h.Reconfigure(&config.Config{}, nil, before.backends(), before.aliases(), before.policy())
+	if h.view().catalogTranslator() != mapped {
+		t.Error("expected a nil translator to keep the current one")
+	}
+}
@@ -37,7 +37,8 @@ func (h *Handlers) CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+PipelinesVersionHeader)
+		w.Header().Set("Access-Control-Expose-Headers", SourceHeader+", "+ResolvedVersionHeader+", "+CanonicalNameHeader)
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
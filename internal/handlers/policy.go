@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"tekton-hub-proxy/internal/metrics"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/policy"
+
+	"github.com/sirupsen/logrus"
+)
+
+var policyDenials = metrics.NewCounterVec(
+	"tekton_hub_proxy_policy_denials_total",
+	"Resource requests denied by the resource policy.",
+	"catalog", "rule",
+)
+
+// checkPolicy returns the DeniedError of a package the policy denies when
+// served for the Tekton Hub catalog.
+func (v *view) checkPolicy(catalog string, pkg *models.ArtifactHubPackage) error {
+	kind, _ := v.catalogTranslator().Kinds().FromRepositoryKind(pkg.Repository.Kind)
+	resource := policy.Resource{
+		Catalog:           catalog,
+		Kind:              kind,
+		Name:              pkg.Name,
+		Version:           pkg.Version,
+		Signed:            pkg.Signed,
+		Deprecated:        pkg.Deprecated,
+		VerifiedPublisher: pkg.Repository.VerifiedPublisher,
+	}
+	return v.policy().Evaluate(resource).Err(resource)
+}
+
+// allowedSummary reports whether the policy allows a search result. Search
+// results describe the latest version, so version ranges are checked against
+// it and resolving a resource decides on the version served.
+func (v *view) allowedSummary(pkg models.ArtifactHubPackageSummary) bool {
+	catalog, _ := v.catalogTranslator().ArtifactHubToTekton(pkg.Repository.Name)
+	kind, _ := v.catalogTranslator().Kinds().FromRepositoryKind(pkg.Repository.Kind)
+	return v.policy().Evaluate(policy.Resource{
+		Catalog:           catalog,
+		Kind:              kind,
+		Name:              pkg.Name,
+		Version:           pkg.Version,
+		Signed:            pkg.Signed,
+		Deprecated:        pkg.Deprecated,
+		VerifiedPublisher: pkg.Repository.VerifiedPublisher,
+	}).Allowed
+}
+
+// auditDenial logs a request the policy denied.
+func auditDenial(r *http.Request, err error) {
+	var denied *policy.DeniedError
+	if !errors.As(err, &denied) {
+		return
+	}
+	policyDenials.Inc(denied.Resource.Catalog, denied.Decision.Rule)
+
+	logrus.WithFields(logrus.Fields{
+		"audit":       "policy",
+		"catalog":     denied.Resource.Catalog,
+		"kind":        denied.Resource.Kind,
+		"name":        denied.Resource.Name,
+		"version":     denied.Resource.Version,
+		"rule":        denied.Decision.Rule,
+		"reason":      denied.Decision.Reason,
+		"path":        r.URL.Path,
+		"remote_addr": r.RemoteAddr,
+		"user_agent":  r.UserAgent(),
+	}).Warn("🚫 Resource denied by policy")
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"tekton-hub-proxy/internal/models"
+)
+
+const (
+	// MatchContains matches resources whose name contains the queried name.
+	MatchContains = "contains"
+	// MatchExact matches resources named exactly like the queried name.
+	MatchExact = "exact"
+
+	defaultQueryLimit = 1000
+
+	// enrichedQueryLimit bounds the search results of queries filtering on
+	// fields that need the full packages, each of them is fetched.
+	enrichedQueryLimit = 200
+	// enrichWorkers bounds the packages fetched at once.
+	enrichWorkers = 8
+)
+
+// resourceQuery holds the filters of a /v1/query request. Filters are
+// combined with AND, the values of one filter with OR, as in Tekton Hub.
+type resourceQuery struct {
+	Name       string
+	Match      string
+	Catalogs   []string
+	Kinds      []string
+	Categories []string
+	Tags       []string
+	Platforms  []string
+	Limit      int
+}
+
+func parseResourceQuery(query url.Values) (resourceQuery, error) {
+	q := resourceQuery{
+		Name:       strings.TrimSpace(query.Get("name")),
+		Match:      strings.ToLower(query.Get("match")),
+		Catalogs:   splitValues(query["catalogs"]),
+		Kinds:      splitValues(query["kinds"]),
+		Categories: splitValues(query["categories"]),
+		Tags:       splitValues(query["tags"]),
+		Platforms:  splitValues(query["platforms"]),
+		Limit:      defaultQueryLimit,
+	}
+
+	switch q.Match {
+	case "":
+		q.Match = MatchContains
+	case MatchContains, MatchExact:
+	default:
+		return q, fmt.Errorf("invalid match %q, expected %q or %q", q.Match, MatchExact, MatchContains)
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			q.Limit = limit
+		}
+	}
+	return q, nil
+}
+
+// needsPackages reports whether the query filters on fields missing from
+// Artifact Hub search results, which then need the full packages.
+func (q resourceQuery) needsPackages() bool {
+	return len(q.Categories) > 0 || len(q.Tags) > 0 || len(q.Platforms) > 0
+}
+
+func (q resourceQuery) matchesName(name string) bool {
+	if q.Name == "" {
+		return true
+	}
+	if q.Match == MatchExact {
+		return strings.EqualFold(name, q.Name)
+	}
+	return strings.Contains(strings.ToLower(name), strings.ToLower(q.Name))
+}
+
+func (q resourceQuery) matches(resource models.TektonHubResource) bool {
+	if !q.matchesName(resource.Name) {
+		return false
+	}
+	if len(q.Catalogs) > 0 && !containsFold(q.Catalogs, resource.Catalog.Name) {
+		return false
+	}
+	if len(q.Kinds) > 0 && !containsFold(q.Kinds, resource.Kind) {
+		return false
+	}
+	if len(q.Categories) > 0 && !anyFold(q.Categories, len(resource.Categories), func(i int) string { return resource.Categories[i].Name }) {
+		return false
+	}
+	if len(q.Tags) > 0 && !anyFold(q.Tags, len(resource.Tags), func(i int) string { return resource.Tags[i].Name }) {
+		return false
+	}
+	if len(q.Platforms) > 0 && !anyFold(q.Platforms, len(resource.Platforms), func(i int) string { return resource.Platforms[i].Name }) {
+		return false
+	}
+	return true
+}
+
+// filter returns the matching resources, at most Limit of them.
+func (q resourceQuery) filter(resources []models.TektonHubResource) []models.TektonHubResource {
+	var matched []models.TektonHubResource
+	for _, resource := range resources {
+		if len(matched) == q.Limit {
+			break
+		}
+		if q.matches(resource) {
+			matched = append(matched, resource)
+		}
+	}
+	return matched
+}
+
+// anyFold reports whether one of the n names returned by name is in values.
+func anyFold(values []string, n int, name func(int) string) bool {
+	for i := 0; i < n; i++ {
+		if containsFold(values, name(i)) {
+			return true
+		}
+	}
+	return false
+}
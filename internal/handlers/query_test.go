@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"testing"
+)
+
+func TestResourceQuery_Filter(t *testing.T) {
+	resources := []models.TektonHubResource{
+		{
+			Name:       "git-clone",
+			Kind:       "task",
+			Catalog:    models.TektonHubCatalog{Name: "tekton"},
+			Categories: []models.TektonHubCategory{{Name: "Git"}},
+			Tags:       []models.TektonHubTag{{Name: "git"}},
+			Platforms:  []models.TektonHubPlatform{{Name: "linux/amd64"}, {Name: "linux/arm64"}},
+		},
+		{
+			Name:       "git-batch-merge",
+			Kind:       "task",
+			Catalog:    models.TektonHubCatalog{Name: "tekton"},
+			Categories: []models.TektonHubCategory{{Name: "Git"}},
+			Tags:       []models.TektonHubTag{{Name: "git"}, {Name: "merge"}},
+			Platforms:  []models.TektonHubPlatform{{Name: "linux/amd64"}},
+		},
+		{
+			Name:       "buildah",
+			Kind:       "task",
+			Catalog:    models.TektonHubCatalog{Name: "community"},
+			Categories: []models.TektonHubCategory{{Name: "Image Build"}},
+			Tags:       []models.TektonHubTag{{Name: "image-build"}},
+			Platforms:  []models.TektonHubPlatform{{Name: "linux/amd64"}, {Name: "linux/s390x"}},
+		},
+		{
+			Name:    "clone-pipeline",
+			Kind:    "pipeline",
+			Catalog: models.TektonHubCatalog{Name: "community"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "no filters", query: "", expected: []string{"git-clone", "git-batch-merge", "buildah", "clone-pipeline"}},
+		{name: "contains by default", query: "name=clone", expected: []string{"git-clone", "clone-pipeline"}},
+		{name: "exact", query: "name=Git-Clone&match=exact", expected: []string{"git-clone"}},
+		{name: "exact without match", query: "name=clone&match=exact", expected: nil},
+		{name: "tags are or'd", query: "tags=merge&tags=image-build", expected: []string{"git-batch-merge", "buildah"}},
+		{name: "filters are and'd", query: "name=git&tags=merge", expected: []string{"git-batch-merge"}},
+		{name: "categories", query: "categories=git", expected: []string{"git-clone", "git-batch-merge"}},
+		{name: "platforms", query: "platforms=linux/arm64,linux/s390x", expected: []string{"git-clone", "buildah"}},
+		{name: "catalogs and kinds", query: "catalogs=community&kinds=pipeline", expected: []string{"clone-pipeline"}},
+		{name: "limit", query: "name=git&limit=1", expected: []string{"git-clone"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, _ := url.ParseQuery(tt.query)
+			q, err := parseResourceQuery(values)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, resource := range q.filter(resources) {
+				names = append(names, resource.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestParseResourceQuery_InvalidMatch(t *testing.T) {
+	if _, err := parseResourceQuery(url.Values{"match": {"fuzzy"}}); err == nil {
+		t.Error("expected error for invalid match")
+	}
+}
+
+func TestHandlers_QueryResources_Enrichment(t *testing.T) {
+	var packages []models.ArtifactHubPackage
+	for i := 0; i < 300; i++ {
+		packages = append(packages, models.ArtifactHubPackage{
+			Name:     fmt.Sprintf("task-%03d", i),
+			Version:  "0.1.0",
+			Keywords: []string{"git"},
+		})
+	}
+	b := newFakeBackend("tekton-catalog-tasks", packages...)
+	cfg := &config.Config{CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}}
+	h := newTestHandlers(t, cfg, b, nil)
+
+	response := serve(h.QueryResources, "/v1/query?tags=git&limit=10", nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, response.Code, response.Body)
+	}
+	var resources models.TektonHubResourcesResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &resources); err != nil {
+		t.Fatal(err)
+	}
+	if len(resources.Data) != 10 || resources.Data[0].Name != "task-000" {
+		t.Errorf("expected the first 10 resources, got %d", len(resources.Data))
+	}
+	// Fetching stops at the batch reaching the limit
+	if fetches := b.fetches.Load(); fetches > 2*enrichWorkers {
+		t.Errorf("expected at most %d packages fetched, got %d", 2*enrichWorkers, fetches)
+	}
+
+	b.fetches.Store(0)
+	serve(h.QueryResources, "/v1/query?tags=missing", nil)
+	if fetches := b.fetches.Load(); fetches != enrichedQueryLimit {
+		t.Errorf("expected the search to be limited to %d packages, got %d fetched", enrichedQueryLimit, fetches)
+	}
+}
+
+func TestHandlers_SearchPolicy(t *testing.T) {
+	b := newFakeBackend("tekton-catalog-tasks",
+		models.ArtifactHubPackage{Name: "git-clone", Version: "0.9.0"},
+		models.ArtifactHubPackage{Name: "buildah", Version: "0.6.0"},
+		models.ArtifactHubPackage{Name: "kaniko", Version: "0.7.0"},
+	)
+	cfg := &config.Config{
+		CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}},
+		Policy: config.PolicyConfig{Default: config.PolicyDeny, Rules: []config.PolicyRule{
+			{Name: "recent", Action: config.PolicyAllow, Names: []string{"git-clone", "buildah"}, Versions: ">=0.7"},
+		}},
+	}
+	h := newTestHandlers(t, cfg, b, nil)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+	}{
+		{name: "query", handler: h.QueryResources, path: "/v1/query?kinds=task"},
+		{name: "list", handler: h.ListResources, path: "/v1/resources"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := serve(tt.handler, tt.path, nil)
+			if response.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, response.Code, response.Body)
+			}
+			var resources models.TektonHubResourcesResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &resources); err != nil {
+				t.Fatal(err)
+			}
+			if len(resources.Data) != 1 || resources.Data[0].Name != "git-clone" {
+				t.Errorf("expected only git-clone allowed by its version, got %+v", resources.Data)
+			}
+		})
+	}
+}
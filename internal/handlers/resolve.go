@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/policy"
+	"tekton-hub-proxy/internal/translator"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SourceHeader names the target catalog a resource was served from.
+const SourceHeader = "X-Tekton-Hub-Proxy-Source"
+
+// ResolvedVersionHeader reports the concrete version a requested version or
+// constraint resolved to.
+const ResolvedVersionHeader = "X-Tekton-Hub-Proxy-Resolved-Version"
+
+// CanonicalNameHeader names the resource, as catalog/kind/name, an aliased
+// request was redirected to.
+const CanonicalNameHeader = "X-Tekton-Hub-Proxy-Canonical-Name"
+
+// PipelinesVersionHeader and PipelinesVersionParam carry the Tekton
+// Pipelines version of the client, latest versions are then limited to the
+// ones the client can run.
+const (
+	PipelinesVersionHeader = "X-Tekton-Pipelines-Version"
+	PipelinesVersionParam  = "pipelinesVersion"
+)
+
+// getPackage looks the resource up in the targets of the catalog in order
+// and returns the first hit. Only targets not having the resource or version
+// fall through to the next one, failing targets fail the lookup so internal
+// overrides are never silently replaced by the next target. Renamed
+// resources are looked up by their new name. An empty version returns the
+// latest version compatible with the client's Tekton Pipelines, constraints
+// resolve to the highest version of the target satisfying them. Packages
+// the resource policy denies fail with a policy.DeniedError.
+func (v *view) getPackage(w http.ResponseWriter, r *http.Request, catalog, kind, name, version string) (*models.ArtifactHubPackage, error) {
+	if canonical, ok := v.aliases().Resolve(catalog, kind, name, version); ok {
+		logrus.WithFields(logrus.Fields{
+			"alias":     fmt.Sprintf("%s/%s/%s", catalog, kind, name),
+			"canonical": fmt.Sprintf("%s/%s/%s", canonical.Catalog, canonical.Kind, canonical.Name),
+			"version":   version,
+		}).Info("🔀 Resource alias used")
+		catalog, kind, name = canonical.Catalog, canonical.Kind, canonical.Name
+		w.Header().Set(CanonicalNameHeader, fmt.Sprintf("%s/%s/%s", catalog, kind, name))
+	}
+
+	if v.config().StrictCatalogs {
+		if err := v.catalogTranslator().CheckCatalog(catalog); err != nil {
+			return nil, err
+		}
+	}
+
+	// Convert kind to repo kind
+	repoKind, err := v.catalogTranslator().KindToRepoKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(version, translator.LatestVersion) {
+		version = ""
+	}
+	pipelinesVersion, err := v.pipelinesVersion(r)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err := v.catalogTranslator().KindTargets(catalog, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, target := range targets {
+		// Versions are resolved against the versions the target has
+		b := v.backends().For(target)
+		var requested string
+		err = nil
+		if version != "" {
+			requested, err = v.resolveVersion(b, repoKind, target, name, version)
+			if errors.Is(err, translator.ErrInvalidVersionConstraint) {
+				return nil, err
+			}
+		}
+
+		var pkg *models.ArtifactHubPackage
+		if err == nil {
+			if requested == "" {
+				pkg, err = v.latestVersion(b, catalog, repoKind, target, name, pipelinesVersion)
+			} else if pkg, err = b.GetVersion(repoKind, target, name, requested); err == nil {
+				err = v.checkPolicy(catalog, pkg)
+			}
+		}
+		if errors.Is(err, policy.ErrDenied) {
+			auditDenial(r, err)
+			return nil, err
+		}
+		if err != nil && !errors.Is(err, client.ErrNotFound) && !errors.Is(err, translator.ErrNoMatchingVersion) {
+			return nil, fmt.Errorf("target %s: %w", target, err)
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"catalog":   catalog,
+				"target":    target,
+				"repo_kind": repoKind,
+				"name":      name,
+				"version":   version,
+				"error":     err.Error(),
+			}).Debug("Resource not served by target, trying next")
+			lastErr = err
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"original_catalog":     catalog,
+			"translated_catalog":   target,
+			"original_kind":        kind,
+			"translated_repo_kind": repoKind,
+			"name":                 name,
+			"requested_version":    version,
+			"version":              pkg.Version,
+		}).Info("🔍 Translation details")
+
+		resolvedVersion, _ := v.versionTranslator.ArtifactHubToTektonAmong(pkg.Version, translator.AvailableVersionStrings(pkg))
+		w.Header().Set(SourceHeader, target)
+		w.Header().Set(ResolvedVersionHeader, resolvedVersion)
+		return pkg, nil
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("catalog %s has no targets: %w", catalog, translator.ErrUnknownCatalog)
+	}
+	return nil, lastErr
+}
+
+// resolveVersion returns the version of the resource in the target the
+// requested version or constraint names.
+func (v *view) resolveVersion(b backend.Backend, repoKind, target, name, requested string) (string, error) {
+	versions, err := b.ListVersions(repoKind, target, name)
+	if err != nil {
+		return "", err
+	}
+
+	available := make([]string, 0, len(versions))
+	for _, v := range versions {
+		available = append(available, v.Version)
+	}
+	if v.versionTranslator.IsExactVersion(requested) {
+		return v.versionTranslator.ResolveAvailable(requested, available)
+	}
+	return v.versionTranslator.ResolveVersion(requested, available)
+}
+
+// pipelinesVersion returns the Tekton Pipelines version of the client: the
+// header, the query parameter or the configured default.
+func (v *view) pipelinesVersion(r *http.Request) (string, error) {
+	pipelinesVersion := r.Header.Get(PipelinesVersionHeader)
+	if pipelinesVersion == "" {
+		pipelinesVersion = r.URL.Query().Get(PipelinesVersionParam)
+	}
+	if pipelinesVersion == "" {
+		pipelinesVersion = v.config().Versions.PipelinesVersion
+	}
+	if err := v.versionTranslator.ValidateVersion(pipelinesVersion); err != nil {
+		return "", fmt.Errorf("pipelines version %q: %w", pipelinesVersion, translator.ErrInvalidVersionConstraint)
+	}
+	return pipelinesVersion, nil
+}
+
+// latestVersion computes the latest version of the resource from its
+// available versions rather than trusting the backend: the newest version
+// the version and resource policies allow that runs on pipelinesVersion.
+// When the resource policy denies every version the newest denial is
+// returned.
+func (v *view) latestVersion(b backend.Backend, catalog, repoKind, target, name, pipelinesVersion string) (*models.ArtifactHubPackage, error) {
+	latest, err := b.GetLatest(repoKind, target, name)
+	if err != nil {
+		return nil, err
+	}
+
+	available := translator.AvailableVersionStrings(latest)
+	if len(available) == 0 {
+		available = []string{latest.Version}
+	}
+	sort.SliceStable(available, func(i, j int) bool {
+		cmp, err := v.versionTranslator.CompareVersions(available[i], available[j])
+		return err == nil && cmp > 0
+	})
+
+	var denied error
+	for _, candidate := range available {
+		if v.config().Versions.ExcludePrereleases && v.versionTranslator.IsPrerelease(candidate) {
+			continue
+		}
+
+		pkg := latest
+		if candidate != latest.Version {
+			pkg, err = b.GetVersion(repoKind, target, name, candidate)
+			if errors.Is(err, client.ErrNotFound) {
+				logrus.WithError(err).WithField("version", candidate).Warn("Listed package version not found, skipping")
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		if v.config().Versions.ExcludeDeprecated && pkg.Deprecated {
+			continue
+		}
+		if !v.versionTranslator.SatisfiesMinVersion(pkg.Data.PipelinesMinVersion, pipelinesVersion) {
+			continue
+		}
+		if err := v.checkPolicy(catalog, pkg); err != nil {
+			if denied == nil {
+				denied = err
+			}
+			continue
+		}
+
+		if pkg.Version != latest.Version {
+			logrus.WithFields(logrus.Fields{
+				"name":              name,
+				"upstream_latest":   latest.Version,
+				"version":           pkg.Version,
+				"pipelines_version": pipelinesVersion,
+			}).Debug("Upstream latest version excluded, serving older version")
+		}
+		return pkg, nil
+	}
+
+	if denied != nil {
+		return nil, denied
+	}
+	if pipelinesVersion != "" {
+		return nil, fmt.Errorf("%s: no version runs on Tekton Pipelines %s: %w", name, pipelinesVersion, translator.ErrNoMatchingVersion)
+	}
+	return nil, fmt.Errorf("%s: every version is excluded by the version policy: %w", name, translator.ErrNoMatchingVersion)
+}
+
+// writePackageError writes the response for a failed getPackage: a bad
+// request for unknown kinds and invalid constraints, forbidden for policy
+// denials, notFoundMessage for missing resources and a bad gateway for
+// failing targets.
+func (h *Handlers) writePackageError(w http.ResponseWriter, err error, notFoundMessage string) {
+	switch {
+	case errors.Is(err, translator.ErrUnknownKind), errors.Is(err, translator.ErrInvalidVersionConstraint):
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, policy.ErrDenied):
+		h.writeErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, translator.ErrNoMatchingVersion), errors.Is(err, translator.ErrUnknownCatalog):
+		h.writeErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, client.ErrNotFound):
+		h.writeErrorResponse(w, http.StatusNotFound, notFoundMessage)
+	default:
+		h.writeErrorResponse(w, http.StatusBadGateway, "failed to fetch resource")
+	}
+}
+
+// repositoryKinds converts Tekton kinds to Artifact Hub repository kinds, all
+// known kinds when none are given.
+func (v *view) repositoryKinds(kinds []string) ([]int, error) {
+	table := v.catalogTranslator().Kinds()
+	if len(kinds) == 0 {
+		return table.RepositoryKinds(), nil
+	}
+
+	var ids []int
+	for _, kind := range kinds {
+		id, err := table.RepositoryKind(kind)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// mappedRepositories returns the repositories to search for every mapped
+// catalog. Catalogs matched by patterns can't be listed, so with patterns
+// every repository is searched and searchPackages drops unmapped ones.
+func (v *view) mappedRepositories() []string {
+	if v.catalogTranslator().HasPatterns() {
+		return nil
+	}
+	return v.catalogTranslator().TargetCatalogs()
+}
+
+// catalogTargets returns the targets of a catalog serving any of the kinds,
+// every kind when none are given.
+func (v *view) catalogTargets(catalog string, kinds []string) []string {
+	if len(kinds) == 0 {
+		return v.catalogTranslator().Targets(catalog)
+	}
+	var targets []string
+	for _, kind := range kinds {
+		kindTargets, err := v.catalogTranslator().KindTargets(catalog, kind)
+		if err != nil {
+			logrus.WithError(err).Debug("Catalog not searched for kind")
+			continue
+		}
+		for _, target := range kindTargets {
+			if !containsFold(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+// searchPackages searches every backend and keeps, for resources offered by
+// several targets of the same catalog, the one with the highest precedence.
+// Resources the resource policy denies are dropped.
+func (v *view) searchPackages(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	result, err := v.backends().Search(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Searches across every repository only keep the mapped ones
+	mappedOnly := len(params.Repositories) == 0 && v.catalogTranslator().HasPatterns()
+
+	seen := make(map[string]int)
+	var packages []models.ArtifactHubPackageSummary
+	for _, pkg := range result.Packages {
+		if mappedOnly && !v.catalogTranslator().IsMapped(pkg.Repository.Name) {
+			continue
+		}
+		if !v.allowedSummary(pkg) {
+			logrus.WithFields(logrus.Fields{
+				"repository": pkg.Repository.Name,
+				"name":       pkg.Name,
+			}).Debug("Search result denied by policy, dropping")
+			continue
+		}
+		tektonCatalog, _ := v.catalogTranslator().ArtifactHubToTekton(pkg.Repository.Name)
+		key := fmt.Sprintf("%s/%d/%s", tektonCatalog, pkg.Repository.Kind, pkg.Name)
+
+		if i, exists := seen[key]; exists {
+			if v.catalogTranslator().Precedence(pkg.Repository.Name) < v.catalogTranslator().Precedence(packages[i].Repository.Name) {
+				packages[i] = pkg
+			}
+			continue
+		}
+		seen[key] = len(packages)
+		packages = append(packages, pkg)
+	}
+
+	result.Packages = packages
+	return result, nil
+}
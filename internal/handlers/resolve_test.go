@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tekton-hub-proxy/internal/backend"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+)
+
+// artifactHubServer answers every package lookup with status, and with pkg
+// when the status is OK. It counts the requests it got.
+func artifactHubServer(t *testing.T, status int, pkg models.ArtifactHubPackage, requests *int) backend.Backend {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_ = json.NewEncoder(w).Encode(pkg)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return backend.NewArtifactHub(client.NewArtifactHubClient(config.ArtifactHubConfig{BaseURL: server.URL, Timeout: 5e9}))
+}
+
+func TestHandlers_GetResource_Targets(t *testing.T) {
+	pkg := func(catalog string) models.ArtifactHubPackage {
+		return models.ArtifactHubPackage{
+			Name:              "git-clone",
+			Version:           "0.9.0",
+			Repository:        models.ArtifactHubRepository{Name: catalog, Kind: 7},
+			AvailableVersions: []models.ArtifactHubVersion{{Version: "0.9.0"}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		internalStatus int
+		expectedStatus int
+		expectedSource string
+		publicRequests int
+	}{
+		{name: "internal override", internalStatus: http.StatusOK, expectedStatus: http.StatusOK, expectedSource: "internal"},
+		{name: "not in internal catalog", internalStatus: http.StatusNotFound, expectedStatus: http.StatusOK, expectedSource: "public", publicRequests: 1},
+		{name: "internal catalog failing", internalStatus: http.StatusInternalServerError, expectedStatus: http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var internalRequests, publicRequests int
+			cfg := &config.Config{CatalogMappings: []config.CatalogMapping{{
+				TektonHub: "tekton",
+				Targets:   []config.CatalogTarget{{ArtifactHub: "internal"}, {ArtifactHub: "public"}},
+			}}}
+			h := newTestHandlers(t, cfg, artifactHubServer(t, http.StatusOK, pkg("public"), &publicRequests), map[string]backend.Backend{
+				"internal": artifactHubServer(t, tt.internalStatus, pkg("internal"), &internalRequests),
+			})
+
+			response := serve(h.GetResource, "/v1/resource/tekton/task/git-clone", map[string]string{"catalog": "tekton", "kind": "task", "name": "git-clone"})
+			if response.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, response.Code, response.Body)
+			}
+			if source := response.Header().Get(SourceHeader); source != tt.expectedSource {
+				t.Errorf("expected source %q, got %q", tt.expectedSource, source)
+			}
+			if publicRequests != tt.publicRequests {
+				t.Errorf("expected %d requests to the public target, got %d", tt.publicRequests, publicRequests)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -27,22 +29,6 @@ func (h *Handlers) GetResourceByID(w http.ResponseWriter, r *http.Request) {
 	h.writeErrorResponse(w, http.StatusNotImplemented, "resource lookup by ID not implemented")
 }
 
-func (h *Handlers) GetResourceVersionsByID(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	idStr := vars["id"]
-
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		h.writeErrorResponse(w, http.StatusBadRequest, "invalid resource ID")
-		return
-	}
-
-	logrus.WithField("id", id).Debug("Getting resource versions by ID")
-
-	// Since we don't have direct ID mapping, we'll return a placeholder error
-	h.writeErrorResponse(w, http.StatusNotImplemented, "resource versions lookup by ID not implemented")
-}
-
 func (h *Handlers) GetResourceByVersionID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	versionIDStr := vars["versionID"]
@@ -60,6 +46,7 @@ func (h *Handlers) GetResourceByVersionID(w http.ResponseWriter, r *http.Request
 }
 
 func (h *Handlers) ListResources(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	logrus.Debug("Listing resources")
 
 	// Parse query parameters
@@ -70,21 +57,18 @@ func (h *Handlers) ListResources(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Search for Tekton packages across all catalogs
+	// Search for Tekton packages of every known kind across all catalogs
 	searchParams := client.SearchParams{
 		Query:  "",
-		Kinds:  []int{12, 13}, // Tekton task and pipeline kinds
+		Kinds:  v.catalogTranslator().Kinds().RepositoryKinds(),
 		Limit:  limit,
 		Facets: false,
 	}
 
 	// Add repositories based on our catalog mappings
-	mappings := h.catalogTranslator.GetAvailableMappings()
-	for _, artifactHubCatalog := range mappings {
-		searchParams.Repositories = append(searchParams.Repositories, artifactHubCatalog)
-	}
+	searchParams.Repositories = v.mappedRepositories()
 
-	searchResult, err := h.artifactHubClient.SearchPackages(searchParams)
+	searchResult, err := v.searchPackages(searchParams)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to search packages")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "failed to list resources")
@@ -92,105 +76,189 @@ func (h *Handlers) ListResources(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Convert to Tekton Hub format
-	response, err := h.responseTranslator.ArtifactHubSearchToTektonResources(searchResult, h.catalogTranslator)
+	response, err := h.responseTranslator.ArtifactHubSearchToTektonResources(searchResult, v.catalogTranslator())
 	if err != nil {
 		logrus.WithError(err).Error("Failed to convert search results")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
 		return
 	}
+	h.rememberResources(response.Data...)
 
 	h.writeJSONResponse(w, http.StatusOK, response)
 }
 
 func (h *Handlers) QueryResources(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
 	logrus.Debug("Querying resources")
 
-	query := r.URL.Query()
+	q, err := parseResourceQuery(r.URL.Query())
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	// Parse query parameters
+	// Parse kinds, defaulting to every known kind
+	kinds, err := v.repositoryKinds(q.Kinds)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Artifact Hub narrows the candidates down by name, kind and catalog,
+	// everything else is filtered on the converted resources
 	searchParams := client.SearchParams{
-		Query:  query.Get("name"),
-		Limit:  1000,
+		Query:  q.Name,
+		Kinds:  kinds,
+		Limit:  defaultQueryLimit,
 		Facets: false,
 	}
-
-	// Parse limit
-	if limitStr := query.Get("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			searchParams.Limit = limit
-		}
+	if q.needsPackages() {
+		searchParams.Limit = enrichedQueryLimit
 	}
 
-	// Parse catalogs
-	if catalogs := query["catalogs"]; len(catalogs) > 0 {
-		for _, catalog := range catalogs {
-			// Convert catalog names to Artifact Hub format
-			if artifactHubCatalog, err := h.catalogTranslator.TektonToArtifactHub(catalog); err == nil {
-				searchParams.Repositories = append(searchParams.Repositories, artifactHubCatalog)
+	if len(q.Catalogs) > 0 {
+		for _, catalog := range q.Catalogs {
+			if v.config().StrictCatalogs {
+				if err := v.catalogTranslator().CheckCatalog(catalog); err != nil {
+					h.writeErrorResponse(w, http.StatusNotFound, err.Error())
+					return
+				}
 			}
+			// Convert catalog names to the targets serving them
+			searchParams.Repositories = append(searchParams.Repositories, v.catalogTargets(catalog, q.Kinds)...)
 		}
-	} else {
-		// If no catalogs specified, search all mapped catalogs
-		mappings := h.catalogTranslator.GetAvailableMappings()
-		for _, artifactHubCatalog := range mappings {
-			searchParams.Repositories = append(searchParams.Repositories, artifactHubCatalog)
-		}
-	}
-
-	// Parse kinds
-	if kinds := query["kinds"]; len(kinds) > 0 {
-		for _, kind := range kinds {
-			switch strings.ToLower(kind) {
-			case "task":
-				searchParams.Kinds = append(searchParams.Kinds, 12) // Tekton task kind
-			case "pipeline":
-				searchParams.Kinds = append(searchParams.Kinds, 13) // Tekton pipeline kind
-			}
+		if len(searchParams.Repositories) == 0 {
+			// None of the catalogs is mapped for the kinds
+			h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+			return
 		}
 	} else {
-		// Default to both tasks and pipelines
-		searchParams.Kinds = []int{12, 13}
-	}
-
-	// Parse categories and tags - these would need more sophisticated mapping
-	// For now, we'll include them in the general query
-	if categories := query["categories"]; len(categories) > 0 {
-		if searchParams.Query != "" {
-			searchParams.Query += " "
-		}
-		searchParams.Query += strings.Join(categories, " ")
-	}
-
-	if tags := query["tags"]; len(tags) > 0 {
-		if searchParams.Query != "" {
-			searchParams.Query += " "
-		}
-		searchParams.Query += strings.Join(tags, " ")
+		// If no catalogs specified, search all mapped catalogs
+		searchParams.Repositories = v.mappedRepositories()
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"query":        searchParams.Query,
+		"match":        q.Match,
 		"kinds":        searchParams.Kinds,
 		"repositories": searchParams.Repositories,
-		"limit":        searchParams.Limit,
+		"categories":   q.Categories,
+		"tags":         q.Tags,
+		"platforms":    q.Platforms,
+		"limit":        q.Limit,
 	}).Debug("Search parameters")
 
 	// Search packages
-	searchResult, err := h.artifactHubClient.SearchPackages(searchParams)
+	searchResult, err := v.searchPackages(searchParams)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to search packages")
 		h.writeErrorResponse(w, http.StatusInternalServerError, "failed to query resources")
 		return
 	}
 
-	// Convert to Tekton Hub format
-	response, err := h.responseTranslator.ArtifactHubSearchToTektonResources(searchResult, h.catalogTranslator)
-	if err != nil {
-		logrus.WithError(err).Error("Failed to convert search results")
-		h.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
+	// Drop name mismatches before fetching any package
+	packages := searchResult.Packages[:0]
+	for _, summary := range searchResult.Packages {
+		if q.matchesName(summary.Name) {
+			packages = append(packages, summary)
+		}
+	}
+	searchResult.Packages = packages
+
+	var resources []models.TektonHubResource
+	if q.needsPackages() {
+		resources = v.packageResources(searchResult, q)
+	} else {
+		// Convert to Tekton Hub format
+		response, err := h.responseTranslator.ArtifactHubSearchToTektonResources(searchResult, v.catalogTranslator())
+		if err != nil {
+			logrus.WithError(err).Error("Failed to convert search results")
+			h.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
+			return
+		}
+		resources = response.Data
+	}
+
+	resources = q.filter(resources)
+	if len(resources) == 0 {
+		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
 		return
 	}
+	h.rememberResources(resources...)
 
-	h.writeJSONResponse(w, http.StatusOK, response)
+	h.writeJSONResponse(w, http.StatusOK, models.TektonHubResourcesResponse{Data: resources})
+}
+
+// packageResources converts the search results matching q from their full
+// packages. Search results lack keywords and the manifest, so the latest
+// version of the results is fetched, enrichWorkers at a time and in order
+// until q.Limit of them matched.
+func (v *view) packageResources(search *models.ArtifactHubSearchResponse, q resourceQuery) []models.TektonHubResource {
+	var resources []models.TektonHubResource
+	for start := 0; start < len(search.Packages) && len(resources) < q.Limit; start += enrichWorkers {
+		batch := search.Packages[start:min(start+enrichWorkers, len(search.Packages))]
+		fetched := make([]*models.TektonHubResource, len(batch))
+
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				fetched[i] = v.packageResource(&batch[i])
+			}(i)
+		}
+		wg.Wait()
+
+		for _, resource := range fetched {
+			if resource != nil && q.matches(*resource) && len(resources) < q.Limit {
+				resources = append(resources, *resource)
+			}
+		}
+	}
+	return resources
+}
+
+// packageResource converts a search result from its full package, nil when
+// it can't be fetched or converted.
+func (v *view) packageResource(summary *models.ArtifactHubPackageSummary) *models.TektonHubResource {
+	repoKind, err := v.catalogTranslator().Kinds().RepoKindFromRepositoryKind(summary.Repository.Kind)
+	if err != nil {
+		return nil
+	}
+	catalog, _ := v.catalogTranslator().ArtifactHubToTekton(summary.Repository.Name)
+	pkg, err := v.latestVersion(v.backends().For(summary.Repository.Name), catalog, repoKind, summary.Repository.Name, summary.Name, "")
+	if err != nil {
+		logrus.WithError(err).WithField("package", summary.Name).Warn("Failed to get package, skipping")
+		return nil
+	}
+	resource, err := v.responseTranslator.ArtifactHubPackageToTektonResource(pkg, v.catalogTranslator())
+	if err != nil {
+		logrus.WithField("package", summary.Name).Warn("Failed to convert package, skipping")
+		return nil
+	}
+	v.hideVersions(pkg, resource)
+	return resource
 }
 
+// splitValues returns the values of a repeated, or comma separated, query
+// parameter.
+func splitValues(values []string) []string {
+	var split []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				split = append(split, part)
+			}
+		}
+	}
+	return split
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/models"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// resourceRef locates a resource by its Tekton Hub coordinates.
+type resourceRef struct {
+	Catalog string
+	Kind    string
+	Name    string
+}
+
+// rememberResources records the IDs of served resources so they can be
+// looked up by ID later.
+func (h *Handlers) rememberResources(resources ...models.TektonHubResource) {
+	h.resourceIDsMutex.Lock()
+	defer h.resourceIDsMutex.Unlock()
+	for _, resource := range resources {
+		h.resourceIDs[resource.ID] = resourceRef{
+			Catalog: resource.Catalog.Name,
+			Kind:    resource.Kind,
+			Name:    resource.Name,
+		}
+	}
+}
+
+// defaultResourceIDsInterval bounds how often unknown IDs list every
+// catalog when responses aren't cached.
+const defaultResourceIDsInterval = time.Minute
+
+// resetResourceIDs lets the next unknown ID list the catalogs of changed
+// settings.
+func (h *Handlers) resetResourceIDs() {
+	h.resourceIDsMutex.Lock()
+	defer h.resourceIDsMutex.Unlock()
+	h.resourceIDsIndexed = time.Time{}
+}
+
+// resourceIDsInterval returns how often unknown IDs may list every catalog,
+// once per cache TTL so the index follows what the cache serves.
+func (v *view) resourceIDsInterval() time.Duration {
+	if cache := v.config().ArtifactHub.Cache; cache.Enabled && cache.TTL > 0 {
+		return cache.TTL
+	}
+	return defaultResourceIDsInterval
+}
+
+// lookupResourceID returns the resource with the given ID. IDs not served
+// before are resolved by listing every catalog, at most once per interval
+// so requests for unknown IDs don't search every time.
+func (v *view) lookupResourceID(id int) (resourceRef, bool) {
+	v.resourceIDsMutex.Lock()
+	ref, exists := v.resourceIDs[id]
+	indexing := !exists && time.Since(v.resourceIDsIndexed) >= v.resourceIDsInterval()
+	if indexing {
+		v.resourceIDsIndexed = time.Now()
+	}
+	v.resourceIDsMutex.Unlock()
+	if !indexing {
+		return ref, exists
+	}
+
+	searchResult, err := v.searchPackages(client.SearchParams{
+		Kinds:        v.catalogTranslator().Kinds().RepositoryKinds(),
+		Repositories: v.mappedRepositories(),
+		Limit:        1000,
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to search packages for resource ID")
+		return resourceRef{}, false
+	}
+	resources, err := v.responseTranslator.ArtifactHubSearchToTektonResources(searchResult, v.catalogTranslator())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to convert search results")
+		return resourceRef{}, false
+	}
+	v.rememberResources(resources.Data...)
+
+	v.resourceIDsMutex.RLock()
+	defer v.resourceIDsMutex.RUnlock()
+	ref, exists = v.resourceIDs[id]
+	return ref, exists
+}
+
+func (h *Handlers) GetResourceVersions(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
+	vars := mux.Vars(r)
+	v.writeResourceVersions(w, r, resourceRef{
+		Catalog: vars["catalog"],
+		Kind:    vars["kind"],
+		Name:    vars["name"],
+	})
+}
+
+func (h *Handlers) GetResourceVersionsByID(w http.ResponseWriter, r *http.Request) {
+	v := h.view()
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.writeErrorResponse(w, http.StatusBadRequest, "invalid resource ID")
+		return
+	}
+
+	logrus.WithField("id", id).Debug("Getting resource versions by ID")
+
+	ref, exists := v.lookupResourceID(id)
+	if !exists {
+		h.writeErrorResponse(w, http.StatusNotFound, "resource not found")
+		return
+	}
+	v.writeResourceVersions(w, r, ref)
+}
+
+func (v *view) writeResourceVersions(w http.ResponseWriter, r *http.Request, ref resourceRef) {
+	logrus.WithFields(logrus.Fields{
+		"catalog": ref.Catalog,
+		"kind":    ref.Kind,
+		"name":    ref.Name,
+	}).Debug("Getting resource versions")
+
+	pkg, err := v.getPackage(w, r, ref.Catalog, ref.Kind, ref.Name, "")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get package")
+		v.writePackageError(w, err, "resource not found")
+		return
+	}
+
+	response, err := v.responseTranslator.ArtifactHubPackageToTektonVersions(pkg, v.catalogTranslator(), baseURL(r))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to convert package versions")
+		v.writeErrorResponse(w, http.StatusInternalServerError, "conversion error")
+		return
+	}
+
+	hidden := v.hiddenVersions(pkg)
+	versions := response.Data.Versions[:0]
+	for _, version := range response.Data.Versions {
+		if !hidden[version.ID] {
+			versions = append(versions, version)
+		}
+	}
+	response.Data.Versions = versions
+
+	v.writeJSONResponse(w, http.StatusOK, response)
+}
+
+// hideVersions drops the versions the version policy hides from the
+// version summaries of a resource.
+func (v *view) hideVersions(pkg *models.ArtifactHubPackage, resource *models.TektonHubResource) {
+	hidden := v.hiddenVersions(pkg)
+	if len(hidden) == 0 {
+		return
+	}
+	versions := resource.Versions[:0]
+	for _, version := range resource.Versions {
+		if !hidden[version.ID] {
+			versions = append(versions, version)
+		}
+	}
+	resource.Versions = versions
+}
+
+// hiddenVersions returns the IDs of the versions of the package excluded by
+// the version policy or denied by the resource policy. Versions are
+// filtered after conversion so the Tekton versions reported for the
+// remaining ones stay resolvable.
+func (v *view) hiddenVersions(pkg *models.ArtifactHubPackage) map[int]bool {
+	versionPolicy := v.config().Versions
+	checkPolicy := !v.policy().AllowsAll()
+	hidden := make(map[int]bool)
+	if !versionPolicy.ExcludePrereleases && !versionPolicy.ExcludeDeprecated && !checkPolicy {
+		return hidden
+	}
+
+	catalog, _ := v.catalogTranslator().ArtifactHubToTekton(pkg.Repository.Name)
+	for _, version := range pkg.AvailableVersions {
+		switch {
+		case versionPolicy.ExcludePrereleases && (version.Prerelease || v.versionTranslator.IsPrerelease(version.Version)):
+		case versionPolicy.ExcludeDeprecated && v.isDeprecated(pkg, version.Version):
+		case checkPolicy && v.deniedVersion(catalog, pkg, version.Version):
+		default:
+			continue
+		}
+		hidden[v.responseTranslator.GenerateVersionID(pkg.PackageID, version.Version)] = true
+	}
+	return hidden
+}
+
+// isDeprecated reports whether a version of the package is deprecated.
+func (v *view) isDeprecated(pkg *models.ArtifactHubPackage, version string) bool {
+	versionPkg := v.versionPackage(pkg, version)
+	return versionPkg != nil && versionPkg.Deprecated
+}
+
+// deniedVersion reports whether the resource policy denies a version of the
+// package served for the Tekton Hub catalog. Versions that can't be fetched
+// are denied, the policy couldn't tell.
+func (v *view) deniedVersion(catalog string, pkg *models.ArtifactHubPackage, version string) bool {
+	versionPkg := v.versionPackage(pkg, version)
+	return versionPkg == nil || v.checkPolicy(catalog, versionPkg) != nil
+}
+
+// versionPackage returns a version of the package, nil when it can't be
+// fetched. Artifact Hub only reports the details of the fetched version,
+// other versions are fetched.
+func (v *view) versionPackage(pkg *models.ArtifactHubPackage, version string) *models.ArtifactHubPackage {
+	if version == pkg.Version {
+		return pkg
+	}
+
+	repoKind, err := v.catalogTranslator().Kinds().RepoKindFromRepositoryKind(pkg.Repository.Kind)
+	if err != nil {
+		return nil
+	}
+
+	versionPkg, err := v.backends().For(pkg.Repository.Name).GetVersion(repoKind, pkg.Repository.Name, pkg.Name, version)
+	if err != nil {
+		logrus.WithError(err).WithField("version", version).Debug("Failed to get package version")
+		return nil
+	}
+	return versionPkg
+}
+
+// baseURL returns the URL clients reached the proxy at.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
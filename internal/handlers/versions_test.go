@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+)
+
+func TestHandlers_LatestVersion(t *testing.T) {
+	prereleaseLatest := []models.ArtifactHubPackage{
+		{Name: "git-clone", Version: "1.0.0-rc.1", Prerelease: true},
+		{Name: "git-clone", Version: "0.9.0"},
+	}
+	deprecatedLatest := []models.ArtifactHubPackage{
+		{Name: "git-clone", Version: "1.0.0", Deprecated: true},
+		{Name: "git-clone", Version: "0.9.0"},
+	}
+	everyVersionExcluded := []models.ArtifactHubPackage{
+		{Name: "git-clone", Version: "1.0.0-rc.1", Prerelease: true},
+		{Name: "git-clone", Version: "0.9.0", Deprecated: true},
+	}
+
+	tests := []struct {
+		name             string
+		packages         []models.ArtifactHubPackage
+		versions         config.VersionsConfig
+		pipelinesVersion string
+		expected         string
+		expectedErr      error
+	}{
+		{name: "prerelease latest", packages: prereleaseLatest, expected: "1.0.0-rc.1"},
+		{name: "prerelease latest excluded", packages: prereleaseLatest, versions: config.VersionsConfig{ExcludePrereleases: true}, expected: "0.9.0"},
+		{name: "deprecated latest", packages: deprecatedLatest, expected: "1.0.0"},
+		{name: "deprecated latest excluded", packages: deprecatedLatest, versions: config.VersionsConfig{ExcludeDeprecated: true}, expected: "0.9.0"},
+		{
+			name:        "every version excluded",
+			packages:    everyVersionExcluded,
+			versions:    config.VersionsConfig{ExcludePrereleases: true, ExcludeDeprecated: true},
+			expectedErr: translator.ErrNoMatchingVersion,
+		},
+		{
+			name: "newer pipelines required",
+			packages: []models.ArtifactHubPackage{
+				{Name: "git-clone", Version: "1.0.0", Data: models.ArtifactHubPackageData{PipelinesMinVersion: "0.50.0"}},
+				{Name: "git-clone", Version: "0.9.0", Data: models.ArtifactHubPackageData{PipelinesMinVersion: "0.40.0"}},
+			},
+			pipelinesVersion: "0.45.0",
+			expected:         "0.9.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newFakeBackend("tekton-catalog-tasks", tt.packages...)
+			cfg := &config.Config{
+				CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}},
+				Versions:        tt.versions,
+			}
+			h := newTestHandlers(t, cfg, b, nil)
+			repoKind, err := h.view().catalogTranslator().KindToRepoKind("task")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkg, err := h.view().latestVersion(b, "tekton", repoKind, "tekton-catalog-tasks", "git-clone", tt.pipelinesVersion)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pkg.Version != tt.expected {
+				t.Errorf("expected version %s, got %s", tt.expected, pkg.Version)
+			}
+		})
+	}
+}
+
+func TestHandlers_HiddenVersions(t *testing.T) {
+	packages := []models.ArtifactHubPackage{
+		{Name: "git-clone", Version: "1.0.0-rc.1", Prerelease: true},
+		{Name: "git-clone", Version: "0.9.0", Deprecated: true},
+		{Name: "git-clone", Version: "0.8.0"},
+	}
+	everyVersionExcluded := []models.ArtifactHubPackage{
+		{Name: "git-clone", Version: "1.0.0-rc.1", Prerelease: true},
+		{Name: "git-clone", Version: "0.9.0", Deprecated: true},
+		{Name: "git-clone", Version: "0.8.0", Deprecated: true},
+	}
+
+	tests := []struct {
+		name     string
+		packages []models.ArtifactHubPackage
+		versions config.VersionsConfig
+		expected []string
+	}{
+		{name: "nothing excluded", packages: packages},
+		{name: "prereleases", packages: packages, versions: config.VersionsConfig{ExcludePrereleases: true}, expected: []string{"1.0.0-rc.1"}},
+		{name: "deprecated", packages: packages, versions: config.VersionsConfig{ExcludeDeprecated: true}, expected: []string{"0.9.0"}},
+		{
+			name:     "every version excluded",
+			packages: everyVersionExcluded,
+			versions: config.VersionsConfig{ExcludePrereleases: true, ExcludeDeprecated: true},
+			expected: []string{"0.8.0", "0.9.0", "1.0.0-rc.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newFakeBackend("tekton-catalog-tasks", tt.packages...)
+			cfg := &config.Config{
+				CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}},
+				Versions:        tt.versions,
+			}
+			h := newTestHandlers(t, cfg, b, nil)
+			pkg, err := b.GetLatest("tekton-task", "tekton-catalog-tasks", "git-clone")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hidden := h.view().hiddenVersions(pkg)
+			var got []string
+			for _, version := range pkg.AvailableVersions {
+				if hidden[h.responseTranslator.GenerateVersionID(pkg.PackageID, version.Version)] {
+					got = append(got, version.Version)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected hidden versions %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// auditHook records the policy audit log entries.
+type auditHook struct {
+	entries []*logrus.Entry
+}
+
+func (h *auditHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *auditHook) Fire(entry *logrus.Entry) error {
+	if entry.Data["audit"] == "policy" {
+		h.entries = append(h.entries, entry)
+	}
+	return nil
+}
+
+func TestHandlers_GetResourceVersions_Policy(t *testing.T) {
+	b := newFakeBackend("tekton-catalog-tasks",
+		models.ArtifactHubPackage{Name: "git-clone", Version: "1.0.0"},
+		models.ArtifactHubPackage{Name: "git-clone", Version: "0.9.0"},
+		models.ArtifactHubPackage{Name: "buildah", Version: "0.1.0"},
+	)
+	cfg := &config.Config{
+		CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}},
+		Policy: config.PolicyConfig{Default: config.PolicyAllow, Rules: []config.PolicyRule{
+			{Name: "no-git-clone-1", Action: config.PolicyDeny, Names: []string{"git-clone"}, Versions: ">=1.0.0"},
+			{Name: "no-buildah", Action: config.PolicyDeny, Names: []string{"buildah"}},
+		}},
+	}
+	h := newTestHandlers(t, cfg, b, nil)
+
+	hook := &auditHook{}
+	logrus.AddHook(hook)
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	response := serve(h.GetResourceVersions, "/v1/resource/tekton/task/git-clone/versions", map[string]string{"catalog": "tekton", "kind": "task", "name": "git-clone"})
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, response.Code, response.Body)
+	}
+	var versions models.TektonHubVersionsResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions.Data.Versions) != 1 || versions.Data.Versions[0].Version != "0.9" || versions.Data.Latest.Version != "0.9" {
+		t.Errorf("expected only the allowed version 0.9, got %+v", versions.Data)
+	}
+
+	response = serve(h.GetResourceVersions, "/v1/resource/tekton/task/buildah/versions", map[string]string{"catalog": "tekton", "kind": "task", "name": "buildah"})
+	if response.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d: %s", http.StatusForbidden, response.Code, response.Body)
+	}
+	if len(hook.entries) != 1 || hook.entries[0].Data["name"] != "buildah" || hook.entries[0].Data["rule"] != "no-buildah" {
+		t.Errorf("expected one audit entry for buildah, got %d", len(hook.entries))
+	}
+}
+
+func TestHandlers_GetResourceVersionsByID_UnknownIDs(t *testing.T) {
+	b := newFakeBackend("tekton-catalog-tasks", models.ArtifactHubPackage{Name: "git-clone", Version: "0.9.0"})
+	cfg := &config.Config{CatalogMappings: []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}}
+	h := newTestHandlers(t, cfg, b, nil)
+	byID := func(id string) int {
+		return serve(h.GetResourceVersionsByID, "/v1/resource/"+id+"/versions", map[string]string{"id": id}).Code
+	}
+
+	for _, id := range []string{"1", "2", "3"} {
+		if code := byID(id); code != http.StatusNotFound {
+			t.Errorf("expected status %d for unknown ID %s, got %d", http.StatusNotFound, id, code)
+		}
+	}
+	if searches := b.searches.Load(); searches != 1 {
+		t.Errorf("expected unknown IDs to list the catalogs once, got %d searches", searches)
+	}
+
+	// IDs listed by the search are served without searching again
+	var id int
+	h.resourceIDsMutex.RLock()
+	for resourceID := range h.resourceIDs {
+		id = resourceID
+	}
+	h.resourceIDsMutex.RUnlock()
+	if code := byID(strconv.Itoa(id)); code != http.StatusOK {
+		t.Errorf("expected status %d for a listed ID, got %d", http.StatusOK, code)
+	}
+	if searches := b.searches.Load(); searches != 1 {
+		t.Errorf("expected listed IDs to be served from the index, got %d searches", searches)
+	}
+
+	// Changed settings may hold other catalogs
+	v := h.view()
+	h.Reconfigure(v.config(), nil, v.backends(), v.aliases(), v.policy())
+	byID("1")
+	if searches := b.searches.Load(); searches != 2 {
+		t.Errorf("expected a reconfiguration to allow listing the catalogs again, got %d searches", searches)
+	}
+}
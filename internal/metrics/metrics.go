@@ -0,0 +1,163 @@
+// Package metrics exports counters in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OtherLabelValue replaces label values once a counter has MaxSeries
+// series, so label values taken from requests can't grow it unbounded.
+const OtherLabelValue = "_other"
+
+// MaxSeries is the number of label combinations a counter tracks.
+const MaxSeries = 1000
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Registry holds the counters exported together.
+type Registry struct {
+	mutex    sync.Mutex
+	counters []*CounterVec
+}
+
+// Default is the registry served by Handler.
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	count  float64
+}
+
+// NewCounterVec creates a counter in the default registry.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+// NewCounterVec creates a counter in the registry.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*series),
+	}
+	r.mutex.Lock()
+	r.counters = append(r.counters, counter)
+	r.mutex.Unlock()
+	return counter
+}
+
+// Inc increments the series of the label values, given in label order.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the series of the label values.
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if len(values) != len(c.labels) {
+		panic(fmt.Sprintf("metric %s: %d label values for %d labels", c.name, len(values), len(c.labels)))
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := strings.Join(values, "\xff")
+	s, exists := c.series[key]
+	if !exists {
+		if len(c.series) >= MaxSeries {
+			values = make([]string, len(c.labels))
+			for i := range values {
+				values[i] = OtherLabelValue
+			}
+			key = strings.Join(values, "\xff")
+			s, exists = c.series[key]
+		}
+		if !exists {
+			s = &series{values: values}
+			c.series[key] = s
+		}
+	}
+	s.count += delta
+}
+
+// Value returns the count of the series of the label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if s, exists := c.series[strings.Join(values, "\xff")]; exists {
+		return s.count
+	}
+	return 0
+}
+
+// Write writes the counters in the Prometheus text format, series sorted by
+// label values.
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	counters := append([]*CounterVec(nil), r.counters...)
+	r.mutex.Unlock()
+
+	for _, counter := range counters {
+		if err := counter.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		s := c.series[key]
+		var labels []string
+		for i, label := range c.labels {
+			labels = append(labels, fmt.Sprintf(`%s="%s"`, label, labelEscaper.Replace(s.values[i])))
+		}
+		line := c.name
+		if len(labels) > 0 {
+			line += "{" + strings.Join(labels, ",") + "}"
+		}
+		if _, err := fmt.Fprintf(w, "%s %g\n", line, s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the default registry.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Default.Write(w)
+	})
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("lookups_total", "Lookups.", "direction", "catalog")
+
+	counter.Inc("forward", "tekton")
+	counter.Inc("forward", "tekton")
+	counter.Add(3, "reverse", `say "hi"`)
+
+	if got := counter.Value("forward", "tekton"); got != 2 {
+		t.Errorf("expected 2, got %v", got)
+	}
+
+	var out strings.Builder
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `# HELP lookups_total Lookups.
+# TYPE lookups_total counter
+lookups_total{direction="forward",catalog="tekton"} 2
+lookups_total{direction="reverse",catalog="say \"hi\""} 3
+`
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
+
+func TestCounterVec_MaxSeries(t *testing.T) {
+	counter := NewRegistry().NewCounterVec("requests_total", "Requests.", "name")
+	for i := 0; i < MaxSeries+5; i++ {
+		counter.Inc(fmt.Sprintf("name-%d", i))
+	}
+	if got := counter.Value(OtherLabelValue); got != 5 {
+		t.Errorf("expected 5 overflowing increments, got %v", got)
+	}
+}
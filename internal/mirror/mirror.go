@@ -0,0 +1,225 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/snapshot"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotSynced is returned for lookups before the first successful sync.
+var ErrNotSynced = errors.New("mirror has not completed a sync yet")
+
+// Status describes the state of the sync loop.
+type Status struct {
+	Syncing     bool      `json:"syncing"`
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	Packages    int       `json:"packages"`
+	Versions    int       `json:"versions"`
+	Errors      int       `json:"errors"`
+}
+
+// Mirror keeps a local copy of the mapped catalogs and serves every lookup
+// from it. Upstream is only contacted by the periodic sync, a failing sync
+// keeps the last good copy in place.
+type Mirror struct {
+	crawler  *snapshot.Crawler
+	source   string
+	mappings []config.CatalogMapping
+	cfg      config.MirrorConfig
+
+	store atomic.Pointer[snapshot.Store]
+
+	mutex  sync.RWMutex
+	status Status
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var _ client.PackageSource = (*Mirror)(nil)
+
+func NewMirror(crawler *snapshot.Crawler, source string, mappings []config.CatalogMapping, cfg config.MirrorConfig) *Mirror {
+	return &Mirror{
+		crawler:  crawler,
+		source:   source,
+		mappings: mappings,
+		cfg:      cfg,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start loads the persisted copy, if any, and starts the sync loop. The
+// first sync runs immediately. Copies synced from another source or with
+// other catalog mappings are dropped, they would serve the wrong catalogs
+// until then.
+func (m *Mirror) Start() {
+	if m.cfg.Path != "" {
+		if store, err := snapshot.Load(m.cfg.Path); err == nil && !m.matches(store.Metadata()) {
+			logrus.WithField("path", m.cfg.Path).Warn("Persisted mirror was synced with other catalog mappings, waiting for first sync")
+		} else if err == nil {
+			m.store.Store(store)
+			meta := store.Metadata()
+			m.mutex.Lock()
+			m.status.LastSuccess = meta.CreatedAt
+			m.status.Packages = meta.Packages
+			m.status.Versions = meta.Versions
+			m.mutex.Unlock()
+			logrus.WithFields(logrus.Fields{
+				"path":       m.cfg.Path,
+				"created_at": meta.CreatedAt,
+				"packages":   meta.Packages,
+			}).Info("Loaded persisted mirror")
+		} else if !errors.Is(err, os.ErrNotExist) {
+			logrus.WithError(err).Warn("Failed to load persisted mirror, waiting for first sync")
+		}
+	}
+
+	go m.loop()
+}
+
+// matches reports whether a copy was synced from the mirror's source with
+// its catalog mappings. Mappings are compared as persisted.
+func (m *Mirror) matches(meta snapshot.Metadata) bool {
+	if meta.Source != m.source {
+		return false
+	}
+	persisted, err := json.Marshal(meta.CatalogMappings)
+	if err != nil {
+		return false
+	}
+	current, err := json.Marshal(m.mappings)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(persisted, current)
+}
+
+// Stop ends the sync loop and waits for a running sync to finish.
+func (m *Mirror) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Mirror) loop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.Sync(); err != nil {
+			logrus.WithError(err).Error("Mirror sync failed, serving last good copy")
+		}
+
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync crawls upstream and swaps in the new copy when the crawl was
+// complete. A partial crawl is only accepted when there is nothing to
+// serve yet.
+func (m *Mirror) Sync() error {
+	m.mutex.Lock()
+	m.status.Syncing = true
+	m.status.LastAttempt = time.Now().UTC()
+	m.mutex.Unlock()
+
+	store, err := m.crawler.Crawl(m.source, m.mappings)
+	if err == nil && store.Metadata().Errors > 0 && m.store.Load() != nil {
+		err = fmt.Errorf("sync incomplete: %d packages or versions could not be fetched", store.Metadata().Errors)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.status.Syncing = false
+
+	if err != nil {
+		m.status.LastError = err.Error()
+		if store != nil {
+			m.status.Errors = store.Metadata().Errors
+		}
+		return err
+	}
+
+	m.store.Store(store)
+	meta := store.Metadata()
+	m.status.LastSuccess = meta.CreatedAt
+	m.status.LastError = ""
+	m.status.Packages = meta.Packages
+	m.status.Versions = meta.Versions
+	m.status.Errors = meta.Errors
+
+	logrus.WithFields(logrus.Fields{
+		"packages": meta.Packages,
+		"versions": meta.Versions,
+		"errors":   meta.Errors,
+	}).Info("Mirror synced")
+
+	if m.cfg.Path != "" {
+		if err := store.Save(m.cfg.Path); err != nil {
+			logrus.WithError(err).Warn("Failed to persist mirror")
+		}
+	}
+
+	return nil
+}
+
+// Status returns a copy of the current sync status.
+func (m *Mirror) Status() Status {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.status
+}
+
+// HealthStatus reports the sync status. A mirror waiting for its first
+// sync is healthy but not ready.
+func (m *Mirror) HealthStatus() (any, bool) {
+	return m.Status(), true
+}
+
+// Ready reports whether the mirror has a copy to serve from.
+func (m *Mirror) Ready() bool {
+	return m.store.Load() != nil
+}
+
+func (m *Mirror) GetPackage(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	store := m.store.Load()
+	if store == nil {
+		return nil, ErrNotSynced
+	}
+	return store.GetPackage(repoKind, catalog, name, version)
+}
+
+func (m *Mirror) GetPackageLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	store := m.store.Load()
+	if store == nil {
+		return nil, ErrNotSynced
+	}
+	return store.GetPackageLatest(repoKind, catalog, name)
+}
+
+func (m *Mirror) SearchPackages(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	store := m.store.Load()
+	if store == nil {
+		return nil, ErrNotSynced
+	}
+	return store.SearchPackages(params)
+}
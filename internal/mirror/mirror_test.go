@@ -0,0 +1,146 @@
+package mirror
+
+import (
+	"errors"
+	"path/filepath"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/snapshot"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+	"time"
+)
+
+type flakySource struct {
+	down bool
+	pkg  *models.ArtifactHubPackage
+}
+
+func (f *flakySource) GetPackage(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	return f.GetPackageLatest(repoKind, catalog, name)
+}
+
+func (f *flakySource) GetPackageLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	if f.down {
+		return nil, errors.New("HTTP 503")
+	}
+	return f.pkg, nil
+}
+
+func (f *flakySource) SearchPackages(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	if f.down {
+		return nil, errors.New("HTTP 503")
+	}
+	return &models.ArtifactHubSearchResponse{Packages: []models.ArtifactHubPackageSummary{{
+		PackageID:  f.pkg.PackageID,
+		Name:       f.pkg.Name,
+		Repository: f.pkg.Repository,
+	}}}, nil
+}
+
+func newTestMirror(t *testing.T, source *flakySource) *Mirror {
+	mappings := []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}
+	crawler := snapshot.NewCrawler(source, translator.NewCatalogTranslator(mappings, translator.DefaultKindTable()), translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	return NewMirror(crawler, "test", mappings, config.MirrorConfig{
+		Enabled:  true,
+		Interval: time.Hour,
+		Path:     filepath.Join(t.TempDir(), "mirror.tar.gz"),
+	})
+}
+
+func TestMirrorServesLastGoodSync(t *testing.T) {
+	source := &flakySource{pkg: &models.ArtifactHubPackage{
+		PackageID:         "id",
+		Name:              "git-clone",
+		Version:           "0.1.0",
+		Repository:        models.ArtifactHubRepository{Name: "tekton-catalog-tasks", Kind: 7},
+		AvailableVersions: []models.ArtifactHubVersion{{Version: "0.1.0"}},
+	}}
+	m := newTestMirror(t, source)
+
+	if _, err := m.GetPackageLatest("tekton-task", "tekton-catalog-tasks", "git-clone"); !errors.Is(err, ErrNotSynced) {
+		t.Fatalf("expected ErrNotSynced before first sync, got %v", err)
+	}
+	if m.Ready() {
+		t.Error("expected mirror not to be ready before first sync")
+	}
+
+	if err := m.Sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	source.down = true
+	if err := m.Sync(); err == nil {
+		t.Fatal("expected sync to fail while upstream is down")
+	}
+
+	pkg, err := m.GetPackage("tekton-task", "tekton-catalog-tasks", "git-clone", "0.1.0")
+	if err != nil {
+		t.Fatalf("expected last good copy to be served, got %v", err)
+	}
+	if pkg.Name != "git-clone" {
+		t.Errorf("unexpected package %q", pkg.Name)
+	}
+
+	status := m.Status()
+	if status.LastError == "" || status.LastSuccess.IsZero() || status.Packages != 1 || status.Versions != 1 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if _, healthy := m.HealthStatus(); !healthy || !m.Ready() {
+		t.Error("expected mirror to stay healthy and ready while serving the last good copy")
+	}
+}
+
+func TestMirrorLoadsPersistedCopy(t *testing.T) {
+	source := &flakySource{pkg: &models.ArtifactHubPackage{
+		PackageID:         "id",
+		Name:              "git-clone",
+		Version:           "0.1.0",
+		Repository:        models.ArtifactHubRepository{Name: "tekton-catalog-tasks", Kind: 7},
+		AvailableVersions: []models.ArtifactHubVersion{{Version: "0.1.0"}},
+	}}
+	first := newTestMirror(t, source)
+	if err := first.Sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	source.down = true
+	second := NewMirror(first.crawler, "test", first.mappings, first.cfg)
+	second.Start()
+	defer second.Stop()
+
+	if _, err := second.GetPackageLatest("tekton-task", "tekton-catalog-tasks", "git-clone"); err != nil {
+		t.Fatalf("expected persisted copy to be served, got %v", err)
+	}
+}
+
+func TestMirrorDropsPersistedCopyOfOtherMappings(t *testing.T) {
+	source := &flakySource{pkg: &models.ArtifactHubPackage{
+		PackageID:         "id",
+		Name:              "git-clone",
+		Version:           "0.1.0",
+		Repository:        models.ArtifactHubRepository{Name: "tekton-catalog-tasks", Kind: 7},
+		AvailableVersions: []models.ArtifactHubVersion{{Version: "0.1.0"}},
+	}}
+	first := newTestMirror(t, source)
+	if err := first.Sync(); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+
+	source.down = true
+	mappings := []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "acme-tasks"}}
+	second := NewMirror(first.crawler, "test", mappings, first.cfg)
+	second.Start()
+	defer second.Stop()
+
+	if second.Ready() {
+		t.Error("expected a mirror with changed mappings not to be ready")
+	}
+	if _, healthy := second.HealthStatus(); !healthy {
+		t.Error("expected a mirror waiting for its first sync to be healthy")
+	}
+	if _, err := second.GetPackageLatest("tekton-task", "tekton-catalog-tasks", "git-clone"); !errors.Is(err, ErrNotSynced) {
+		t.Errorf("expected the persisted copy to be dropped, got %v", err)
+	}
+}
@@ -9,11 +9,13 @@ type TektonHubCatalogResponse struct {
 }
 
 type TektonHubCatalog struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Provider string `json:"provider"`
-	Type     string `json:"type"`
-	URL      string `json:"url"`
+	ID                int    `json:"id"`
+	Name              string `json:"name"`
+	DisplayName       string `json:"displayName,omitempty"`
+	Provider          string `json:"provider"`
+	Type              string `json:"type"`
+	URL               string `json:"url"`
+	VerifiedPublisher bool   `json:"verifiedPublisher,omitempty"`
 }
 
 type TektonHubResourceResponse struct {
@@ -36,19 +38,21 @@ type TektonHubResource struct {
 }
 
 type TektonHubResourceVersion struct {
-	ID                  int                 `json:"id"`
-	Version             string              `json:"version"`
-	DisplayName         string              `json:"displayName"`
-	Description         string              `json:"description"`
-	MinPipelinesVersion string              `json:"minPipelinesVersion"`
-	RawURL              string              `json:"rawURL"`
-	WebURL              string              `json:"webURL"`
-	UpdatedAt           time.Time           `json:"updatedAt"`
-	Platforms           []TektonHubPlatform `json:"platforms"`
-	HubURLPath          string              `json:"hubURLPath"`
-	HubRawURLPath       string              `json:"hubRawURLPath"`
-	Resource            *TektonHubResource  `json:"resource,omitempty"`
-	Deprecated          bool                `json:"deprecated"`
+	ID                      int                 `json:"id"`
+	Version                 string              `json:"version"`
+	DisplayName             string              `json:"displayName"`
+	Description             string              `json:"description"`
+	MinPipelinesVersion     string              `json:"minPipelinesVersion"`
+	RawURL                  string              `json:"rawURL"`
+	WebURL                  string              `json:"webURL"`
+	UpdatedAt               time.Time           `json:"updatedAt"`
+	Platforms               []TektonHubPlatform `json:"platforms"`
+	HubURLPath              string              `json:"hubURLPath"`
+	HubRawURLPath           string              `json:"hubRawURLPath"`
+	Resource                *TektonHubResource  `json:"resource,omitempty"`
+	Deprecated              bool                `json:"deprecated"`
+	Prerelease              bool                `json:"prerelease"`
+	ContainsSecurityUpdates bool                `json:"containsSecurityUpdates"`
 }
 
 type TektonHubVersionSummary struct {
@@ -61,6 +65,10 @@ type TektonHubCategory struct {
 	Name string `json:"name"`
 }
 
+type TektonHubCategoriesResponse struct {
+	Data []TektonHubCategory `json:"data"`
+}
+
 type TektonHubTag struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -0,0 +1,178 @@
+// Package policy decides which resources the proxy may serve.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/translator"
+
+	"github.com/hashicorp/go-version"
+)
+
+// ErrDenied is returned for resources the policy denies.
+var ErrDenied = errors.New("denied by policy")
+
+// Resource describes a resolved package version by its Tekton Hub
+// coordinates and Artifact Hub flags.
+type Resource struct {
+	Catalog           string
+	Kind              string
+	Name              string
+	Version           string
+	Signed            bool
+	Deprecated        bool
+	VerifiedPublisher bool
+}
+
+func (r Resource) String() string {
+	return fmt.Sprintf("%s/%s/%s@%s", r.Catalog, r.Kind, r.Name, r.Version)
+}
+
+// DefaultRule names the default action in decisions.
+const DefaultRule = "default"
+
+// Decision is the outcome of evaluating a resource and the rule that
+// applied.
+type Decision struct {
+	Allowed bool
+	Rule    string
+	Reason  string
+}
+
+// DeniedError is returned for a resource the policy denies, it matches
+// ErrDenied.
+type DeniedError struct {
+	Resource Resource
+	Decision Decision
+}
+
+func (e *DeniedError) Error() string {
+	if e.Decision.Reason != "" {
+		return fmt.Sprintf("%s %s, rule %s: %s", e.Resource, ErrDenied, e.Decision.Rule, e.Decision.Reason)
+	}
+	return fmt.Sprintf("%s %s, rule %s", e.Resource, ErrDenied, e.Decision.Rule)
+}
+
+func (e *DeniedError) Is(target error) bool {
+	return target == ErrDenied
+}
+
+// Err returns the DeniedError of a denial, nil when allowed.
+func (d Decision) Err(resource Resource) error {
+	if d.Allowed {
+		return nil
+	}
+	return &DeniedError{Resource: resource, Decision: d}
+}
+
+// Engine evaluates resources against the configured rules.
+type Engine struct {
+	defaultAllow bool
+	rules        []rule
+}
+
+type rule struct {
+	config.PolicyRule
+	versions version.Constraints
+}
+
+// NewEngine checks the rules and parses their version ranges, which accept
+// the same constraints as requested versions.
+func NewEngine(cfg config.PolicyConfig, versionTranslator *translator.VersionTranslator) (*Engine, error) {
+	engine := &Engine{}
+	switch strings.ToLower(cfg.Default) {
+	case "", config.PolicyAllow:
+		engine.defaultAllow = true
+	case config.PolicyDeny:
+	default:
+		return nil, fmt.Errorf("invalid default action %q, expected %q or %q", cfg.Default, config.PolicyAllow, config.PolicyDeny)
+	}
+
+	for i, r := range cfg.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("#%d", i+1)
+		}
+		r.Action = strings.ToLower(r.Action)
+		if r.Action != config.PolicyAllow && r.Action != config.PolicyDeny {
+			return nil, fmt.Errorf("rule %s: invalid action %q, expected %q or %q", r.Name, r.Action, config.PolicyAllow, config.PolicyDeny)
+		}
+		for _, name := range r.Names {
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, fmt.Errorf("rule %s: name %q: %w", r.Name, name, err)
+			}
+		}
+
+		compiled := rule{PolicyRule: r}
+		if r.Versions != "" {
+			constraints, err := versionTranslator.ParseConstraint(r.Versions)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+			compiled.versions = constraints
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+// AllowsAll reports whether the engine allows every resource, without
+// having to look at any.
+func (e *Engine) AllowsAll() bool {
+	return e.defaultAllow && len(e.rules) == 0
+}
+
+// Evaluate returns the decision of the first rule matching the resource.
+func (e *Engine) Evaluate(resource Resource) Decision {
+	for _, r := range e.rules {
+		if r.matches(resource) {
+			return Decision{Allowed: r.Action == config.PolicyAllow, Rule: r.Name, Reason: r.Reason}
+		}
+	}
+	return Decision{Allowed: e.defaultAllow, Rule: DefaultRule}
+}
+
+func (r rule) matches(resource Resource) bool {
+	if len(r.Catalogs) > 0 && !contains(r.Catalogs, resource.Catalog, false) {
+		return false
+	}
+	if len(r.Kinds) > 0 && !contains(r.Kinds, resource.Kind, true) {
+		return false
+	}
+	if len(r.Names) > 0 && !matchesName(r.Names, resource.Name) {
+		return false
+	}
+	if r.versions != nil {
+		v, err := version.NewVersion(resource.Version)
+		if err != nil || !r.versions.Check(v) {
+			return false
+		}
+	}
+	return matchesFlag(r.Signed, resource.Signed) &&
+		matchesFlag(r.Deprecated, resource.Deprecated) &&
+		matchesFlag(r.VerifiedPublisher, resource.VerifiedPublisher)
+}
+
+func contains(values []string, value string, fold bool) bool {
+	for _, v := range values {
+		if v == value || (fold && strings.EqualFold(v, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesFlag(expected *bool, actual bool) bool {
+	return expected == nil || *expected == actual
+}
@@ -0,0 +1,70 @@
+package policy
+
+import (
+	"errors"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	yes, no := true, false
+	engine, err := NewEngine(config.PolicyConfig{
+		Default: config.PolicyDeny,
+		Rules: []config.PolicyRule{
+			{Name: "no-deprecated", Action: config.PolicyDeny, Deprecated: &yes},
+			{Name: "git-clone-cve", Action: config.PolicyDeny, Names: []string{"git-clone"}, Versions: ">=0.7, <0.9", Reason: "CVE-2023-0001"},
+			{Name: "unverified", Action: config.PolicyDeny, Signed: &no, VerifiedPublisher: &no},
+			{Action: config.PolicyAllow, Catalogs: []string{"tekton"}, Kinds: []string{"Task"}, Names: []string{"git-*", "buildah"}},
+		},
+	}, translator.NewVersionTranslator())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	allowed := Resource{Catalog: "tekton", Kind: "task", Name: "git-clone", Version: "0.9.0", VerifiedPublisher: true}
+
+	tests := []struct {
+		name    string
+		modify  func(*Resource)
+		allowed bool
+		rule    string
+	}{
+		{name: "allowed", modify: func(r *Resource) {}, allowed: true, rule: "#4"},
+		{name: "deprecated", modify: func(r *Resource) { r.Deprecated = true }, rule: "no-deprecated"},
+		{name: "blocked version", modify: func(r *Resource) { r.Version = "0.8.1" }, rule: "git-clone-cve"},
+		{name: "blocked version of other name", modify: func(r *Resource) { r.Name, r.Version = "git-batch-merge", "0.8.1" }, allowed: true, rule: "#4"},
+		{name: "neither signed nor verified", modify: func(r *Resource) { r.VerifiedPublisher = false }, rule: "unverified"},
+		{name: "signed only", modify: func(r *Resource) { r.VerifiedPublisher, r.Signed = false, true }, allowed: true, rule: "#4"},
+		{name: "other catalog", modify: func(r *Resource) { r.Catalog = "community" }, rule: DefaultRule},
+		{name: "other kind", modify: func(r *Resource) { r.Kind = "pipeline" }, rule: DefaultRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := allowed
+			tt.modify(&resource)
+			decision := engine.Evaluate(resource)
+			if decision.Allowed != tt.allowed || decision.Rule != tt.rule {
+				t.Errorf("expected allowed=%v by rule %q, got %+v", tt.allowed, tt.rule, decision)
+			}
+			if err := decision.Err(resource); tt.allowed != (err == nil) || (err != nil && !errors.Is(err, ErrDenied)) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestNewEngine_Invalid(t *testing.T) {
+	tests := []config.PolicyConfig{
+		{Default: "maybe"},
+		{Rules: []config.PolicyRule{{Action: "block"}}},
+		{Rules: []config.PolicyRule{{Action: config.PolicyDeny, Names: []string{"[git"}}}},
+		{Rules: []config.PolicyRule{{Action: config.PolicyDeny, Versions: "not-a-version"}}},
+	}
+	for _, cfg := range tests {
+		if _, err := NewEngine(cfg, translator.NewVersionTranslator()); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
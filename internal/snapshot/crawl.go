@@ -0,0 +1,140 @@
+package snapshot
+
+import (
+	"fmt"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/translator"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// searchPageSize is the largest page Artifact Hub's search API accepts.
+const searchPageSize = 60
+
+// Crawler walks every package and version of the mapped catalogs.
+type Crawler struct {
+	source             client.PackageSource
+	catalogTranslator  *translator.CatalogTranslator
+	responseTranslator *translator.ResponseTranslator
+}
+
+func NewCrawler(
+	source client.PackageSource,
+	catalogTranslator *translator.CatalogTranslator,
+	responseTranslator *translator.ResponseTranslator,
+) *Crawler {
+	return &Crawler{
+		source:             source,
+		catalogTranslator:  catalogTranslator,
+		responseTranslator: responseTranslator,
+	}
+}
+
+// Crawl fetches the package metadata, every version and the search index of
+// the catalogs mapped to the configured Artifact Hub into a new Store. A failing search
+// aborts the crawl, failures on single packages or versions are logged and
+// counted in the snapshot metadata.
+func (c *Crawler) Crawl(sourceName string, mappings []config.CatalogMapping) (*Store, error) {
+	store := newStore()
+	store.meta = Metadata{
+		FormatVersion:   FormatVersion,
+		CreatedAt:       time.Now().UTC(),
+		Source:          sourceName,
+		CatalogMappings: mappings,
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Pattern != "" {
+			logrus.WithField("catalog", mapping.TektonHub).Warn("⚠️  Catalogs matched by patterns can't be listed, skipping mapping")
+			continue
+		}
+		for _, target := range mapping.AllTargets() {
+			if target.BackendName() != config.BackendArtifactHub || target.BaseURL != "" {
+				continue
+			}
+			if err := c.crawlCatalog(store, target.ArtifactHub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	store.meta.Packages = len(store.latest)
+	store.meta.Versions = len(store.versions)
+
+	logrus.WithFields(logrus.Fields{
+		"packages": store.meta.Packages,
+		"versions": store.meta.Versions,
+		"errors":   store.meta.Errors,
+	}).Info("Catalog crawl completed")
+
+	return store, nil
+}
+
+func (c *Crawler) crawlCatalog(store *Store, catalog string) error {
+	for offset := 0; ; offset += searchPageSize {
+		result, err := c.source.SearchPackages(client.SearchParams{
+			Repositories: []string{catalog},
+			Limit:        searchPageSize,
+			Offset:       offset,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list catalog %s: %w", catalog, err)
+		}
+
+		for _, summary := range result.Packages {
+			store.index = append(store.index, summary)
+			c.crawlPackage(store, summary.Repository.Kind, summary.Repository.Name, summary.Name)
+		}
+
+		if len(result.Packages) < searchPageSize {
+			return nil
+		}
+	}
+}
+
+func (c *Crawler) crawlPackage(store *Store, kind int, catalog, name string) {
+	log := logrus.WithFields(logrus.Fields{
+		"repository_kind": kind,
+		"catalog":         catalog,
+		"name":            name,
+	})
+
+	tektonKind, err := c.responseTranslator.Kinds().FromRepositoryKind(kind)
+	if err != nil {
+		log.WithError(err).Warn("Unknown repository kind, skipping")
+		store.meta.Errors++
+		return
+	}
+	repoKind, err := c.catalogTranslator.KindToRepoKind(tektonKind)
+	if err != nil {
+		log.WithError(err).Warn("Unknown kind, skipping")
+		store.meta.Errors++
+		return
+	}
+	log = log.WithField("repo_kind", repoKind)
+
+	latest, err := c.source.GetPackageLatest(repoKind, catalog, name)
+	if err != nil {
+		log.WithError(err).Warn("Failed to fetch package, skipping")
+		store.meta.Errors++
+		return
+	}
+	store.addLatest(repoKind, catalog, name, latest)
+
+	for _, available := range latest.AvailableVersions {
+		pkg := latest
+		if available.Version != latest.Version {
+			pkg, err = c.source.GetPackage(repoKind, catalog, name, available.Version)
+			if err != nil {
+				log.WithError(err).WithField("version", available.Version).Warn("Failed to fetch package version, skipping")
+				store.meta.Errors++
+				continue
+			}
+		}
+		store.versions[versionKey(repoKind, catalog, name, available.Version)] = pkg
+	}
+
+	log.WithField("versions", len(latest.AvailableVersions)).Debug("Package crawled")
+}
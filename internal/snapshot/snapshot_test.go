@@ -0,0 +1,146 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"tekton-hub-proxy/internal/translator"
+	"testing"
+)
+
+type fakeSource struct {
+	packages map[string]*models.ArtifactHubPackage
+	index    []models.ArtifactHubPackageSummary
+}
+
+func (f *fakeSource) GetPackage(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	if pkg, ok := f.packages[catalog+"/"+name+"@"+version]; ok {
+		return pkg, nil
+	}
+	return nil, errors.New("HTTP 404")
+}
+
+func (f *fakeSource) GetPackageLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	if pkg, ok := f.packages[catalog+"/"+name]; ok {
+		return pkg, nil
+	}
+	return nil, errors.New("HTTP 404")
+}
+
+func (f *fakeSource) SearchPackages(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	return &models.ArtifactHubSearchResponse{Packages: f.index}, nil
+}
+
+func newFakeSource() *fakeSource {
+	repo := models.ArtifactHubRepository{Name: "tekton-catalog-tasks", Kind: 7}
+	versions := []models.ArtifactHubVersion{{Version: "0.1.0"}, {Version: "0.2.0"}}
+
+	source := &fakeSource{packages: map[string]*models.ArtifactHubPackage{}}
+	for _, version := range versions {
+		pkg := &models.ArtifactHubPackage{
+			PackageID:         "git-clone-id",
+			Name:              "git-clone",
+			Version:           version.Version,
+			Repository:        repo,
+			AvailableVersions: versions,
+			Keywords:          []string{"git", "scm"},
+			README:            "readme " + version.Version,
+			Data:              models.ArtifactHubPackageData{ManifestRaw: "kind: Task # " + version.Version},
+		}
+		source.packages[fmt.Sprintf("%s/%s@%s", repo.Name, pkg.Name, version.Version)] = pkg
+	}
+	source.packages[repo.Name+"/git-clone"] = source.packages[repo.Name+"/git-clone@0.2.0"]
+	source.index = []models.ArtifactHubPackageSummary{{
+		PackageID:   "git-clone-id",
+		Name:        "git-clone",
+		Description: "Clone a repository",
+		Version:     "0.2.0",
+		Repository:  repo,
+	}}
+	return source
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	mappings := []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}
+	crawler := NewCrawler(newFakeSource(), translator.NewCatalogTranslator(mappings, translator.DefaultKindTable()), translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+
+	crawled, err := crawler.Crawl("test", mappings)
+	if err != nil {
+		t.Fatalf("crawl failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := crawled.Write(&buf); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	store, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	meta := store.Metadata()
+	if meta.Packages != 1 || meta.Versions != 2 || meta.Errors != 0 {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	if len(meta.CatalogMappings) != 1 || meta.CatalogMappings[0].TektonHub != "tekton" {
+		t.Errorf("catalog mappings not preserved: %+v", meta.CatalogMappings)
+	}
+
+	latest, err := store.GetPackageLatest("tekton-task", "tekton-catalog-tasks", "git-clone")
+	if err != nil {
+		t.Fatalf("latest lookup failed: %v", err)
+	}
+	if latest.Version != "0.2.0" {
+		t.Errorf("expected latest 0.2.0, got %s", latest.Version)
+	}
+
+	old, err := store.GetPackage("tekton-task", "tekton-catalog-tasks", "git-clone", "0.1.0")
+	if err != nil {
+		t.Fatalf("version lookup failed: %v", err)
+	}
+	if old.README != "readme 0.1.0" || old.Data.ManifestRaw != "kind: Task # 0.1.0" {
+		t.Errorf("unexpected package content: %+v", old)
+	}
+
+	if _, err := store.GetPackage("tekton-task", "tekton-catalog-tasks", "git-clone", "9.9.9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStoreSearchPackages(t *testing.T) {
+	mappings := []config.CatalogMapping{{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"}}
+	crawler := NewCrawler(newFakeSource(), translator.NewCatalogTranslator(mappings, translator.DefaultKindTable()), translator.NewResponseTranslator(translator.DefaultKindTable(), translator.NewVersionTranslator(), translator.DefaultCategoryTable()))
+	store, err := crawler.Crawl("test", mappings)
+	if err != nil {
+		t.Fatalf("crawl failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		params   client.SearchParams
+		expected int
+	}{
+		{name: "no filter", params: client.SearchParams{}, expected: 1},
+		{name: "name term", params: client.SearchParams{Query: "git"}, expected: 1},
+		{name: "keyword term", params: client.SearchParams{Query: "scm clone"}, expected: 1},
+		{name: "unknown term", params: client.SearchParams{Query: "helm"}, expected: 0},
+		{name: "other repository", params: client.SearchParams{Repositories: []string{"other"}}, expected: 0},
+		{name: "offset past end", params: client.SearchParams{Offset: 1}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := store.SearchPackages(tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Packages) != tt.expected {
+				t.Errorf("expected %d packages, got %d", tt.expected, len(result.Packages))
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"tekton-hub-proxy/internal/client"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"time"
+)
+
+// FormatVersion is bumped whenever the archive layout changes.
+const FormatVersion = 1
+
+const (
+	metadataFile = "snapshot.json"
+	indexFile    = "index.json"
+	packagesDir  = "packages"
+	latestFile   = "latest.json"
+	versionsDir  = "versions"
+)
+
+// ErrNotFound is returned when a package or version is not in the snapshot.
+var ErrNotFound = fmt.Errorf("not found in snapshot: %w", client.ErrNotFound)
+
+// Metadata describes where and when a snapshot was taken.
+type Metadata struct {
+	FormatVersion   int                     `json:"format_version"`
+	CreatedAt       time.Time               `json:"created_at"`
+	Source          string                  `json:"source"`
+	CatalogMappings []config.CatalogMapping `json:"catalog_mappings"`
+	Packages        int                     `json:"packages"`
+	Versions        int                     `json:"versions"`
+	Errors          int                     `json:"errors"`
+}
+
+// Store is an in-memory copy of the mapped catalogs. It answers the same
+// lookups as the Artifact Hub client without touching the network.
+type Store struct {
+	meta     Metadata
+	index    []models.ArtifactHubPackageSummary
+	latest   map[string]*models.ArtifactHubPackage
+	versions map[string]*models.ArtifactHubPackage
+	keywords map[string][]string
+}
+
+var _ client.PackageSource = (*Store)(nil)
+
+func newStore() *Store {
+	return &Store{
+		latest:   make(map[string]*models.ArtifactHubPackage),
+		versions: make(map[string]*models.ArtifactHubPackage),
+		keywords: make(map[string][]string),
+	}
+}
+
+func (s *Store) addLatest(repoKind, catalog, name string, pkg *models.ArtifactHubPackage) {
+	s.latest[packageKey(repoKind, catalog, name)] = pkg
+	s.keywords[pkg.PackageID] = pkg.Keywords
+}
+
+func packageKey(repoKind, catalog, name string) string {
+	return repoKind + "/" + catalog + "/" + name
+}
+
+func versionKey(repoKind, catalog, name, version string) string {
+	return packageKey(repoKind, catalog, name) + "@" + version
+}
+
+// Metadata returns the snapshot metadata.
+func (s *Store) Metadata() Metadata {
+	return s.meta
+}
+
+func (s *Store) GetPackage(repoKind, catalog, name, version string) (*models.ArtifactHubPackage, error) {
+	pkg, ok := s.versions[versionKey(repoKind, catalog, name, version)]
+	if !ok {
+		return nil, fmt.Errorf("package %s/%s/%s version %s: %w", repoKind, catalog, name, version, ErrNotFound)
+	}
+	return pkg, nil
+}
+
+func (s *Store) GetPackageLatest(repoKind, catalog, name string) (*models.ArtifactHubPackage, error) {
+	pkg, ok := s.latest[packageKey(repoKind, catalog, name)]
+	if !ok {
+		return nil, fmt.Errorf("package %s/%s/%s: %w", repoKind, catalog, name, ErrNotFound)
+	}
+	return pkg, nil
+}
+
+// SearchPackages emulates the Artifact Hub search endpoint over the
+// snapshot index. Every query term has to match the package name, display
+// name, description or one of its keywords.
+func (s *Store) SearchPackages(params client.SearchParams) (*models.ArtifactHubSearchResponse, error) {
+	terms := strings.Fields(strings.ToLower(params.Query))
+
+	var matched []models.ArtifactHubPackageSummary
+	for _, summary := range s.index {
+		if len(params.Repositories) > 0 && !slices.Contains(params.Repositories, summary.Repository.Name) {
+			continue
+		}
+		if len(params.Kinds) > 0 && !slices.Contains(params.Kinds, summary.Repository.Kind) {
+			continue
+		}
+		if !s.matchesTerms(summary, terms) {
+			continue
+		}
+		matched = append(matched, summary)
+	}
+
+	if params.Offset > 0 {
+		if params.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[params.Offset:]
+		}
+	}
+	if params.Limit > 0 && len(matched) > params.Limit {
+		matched = matched[:params.Limit]
+	}
+
+	return &models.ArtifactHubSearchResponse{Packages: matched}, nil
+}
+
+func (s *Store) matchesTerms(summary models.ArtifactHubPackageSummary, terms []string) bool {
+	if len(terms) == 0 {
+		return true
+	}
+
+	haystack := []string{summary.Name, summary.DisplayName, summary.Description}
+	haystack = append(haystack, s.keywords[summary.PackageID]...)
+	text := strings.ToLower(strings.Join(haystack, " "))
+
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Load reads a snapshot archive from disk.
+func Load(filename string) (*Store, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	return Read(f)
+}
+
+// Read reads a gzipped tar snapshot archive.
+func Read(r io.Reader) (*Store, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer gz.Close() //nolint:errcheck
+
+	store := newStore()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot entry: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := store.readEntry(hdr.Name, tr); err != nil {
+			return nil, fmt.Errorf("snapshot entry %s: %w", hdr.Name, err)
+		}
+	}
+
+	if store.meta.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %d", store.meta.FormatVersion)
+	}
+
+	return store, nil
+}
+
+func (s *Store) readEntry(name string, r io.Reader) error {
+	switch {
+	case name == metadataFile:
+		return json.NewDecoder(r).Decode(&s.meta)
+	case name == indexFile:
+		return json.NewDecoder(r).Decode(&s.index)
+	case strings.HasPrefix(name, packagesDir+"/"):
+		parts := strings.Split(strings.TrimPrefix(name, packagesDir+"/"), "/")
+		for i, part := range parts {
+			unescaped, err := url.PathUnescape(part)
+			if err != nil {
+				return err
+			}
+			parts[i] = unescaped
+		}
+
+		var pkg models.ArtifactHubPackage
+		if err := json.NewDecoder(r).Decode(&pkg); err != nil {
+			return err
+		}
+
+		switch {
+		case len(parts) == 4 && parts[3] == latestFile:
+			s.addLatest(parts[0], parts[1], parts[2], &pkg)
+		case len(parts) == 5 && parts[3] == versionsDir:
+			version := strings.TrimSuffix(parts[4], ".json")
+			s.versions[versionKey(parts[0], parts[1], parts[2], version)] = &pkg
+		default:
+			return fmt.Errorf("unexpected package entry")
+		}
+	}
+	return nil
+}
+
+// Save writes the snapshot archive to disk. The file is written next to
+// its destination first so readers never see a partial archive.
+func (s *Store) Save(filename string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if err := s.Write(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Write writes the snapshot as a gzipped tar archive.
+func (s *Store) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeJSON(tw, metadataFile, s.meta); err != nil {
+		return err
+	}
+	if err := writeJSON(tw, indexFile, s.index); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(s.latest) {
+		parts := strings.SplitN(key, "/", 3)
+		name := path.Join(packagesDir, escape(parts[0]), escape(parts[1]), escape(parts[2]), latestFile)
+		if err := writeJSON(tw, name, s.latest[key]); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range sortedKeys(s.versions) {
+		pkgKey, version, _ := strings.Cut(key, "@")
+		parts := strings.SplitN(pkgKey, "/", 3)
+		name := path.Join(packagesDir, escape(parts[0]), escape(parts[1]), escape(parts[2]), versionsDir, escape(version)+".json")
+		if err := writeJSON(tw, name, s.versions[key]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(tw *tar.Writer, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
+
+func sortedKeys(m map[string]*models.ArtifactHubPackage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
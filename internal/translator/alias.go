@@ -0,0 +1,75 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+	"tekton-hub-proxy/internal/config"
+
+	"github.com/hashicorp/go-version"
+)
+
+// AliasTable redirects requests for renamed resources to their new names.
+type AliasTable struct {
+	aliases  []config.ResourceAlias
+	versions []version.Constraints
+}
+
+// NewAliasTable checks the aliases and parses their version ranges, which
+// accept the same constraints as requested versions.
+func NewAliasTable(aliases []config.ResourceAlias, versionTranslator *VersionTranslator) (*AliasTable, error) {
+	table := &AliasTable{}
+	for _, alias := range aliases {
+		if alias.Name == "" {
+			return nil, fmt.Errorf("alias without name")
+		}
+		if alias.To.Catalog == "" && alias.To.Kind == "" && alias.To.Name == "" {
+			return nil, fmt.Errorf("alias %q has no target", alias.Name)
+		}
+
+		var constraints version.Constraints
+		if alias.Versions != "" {
+			parsed, err := versionTranslator.ParseConstraint(alias.Versions)
+			if err != nil {
+				return nil, fmt.Errorf("alias %q: %w", alias.Name, err)
+			}
+			constraints = parsed
+		}
+
+		table.aliases = append(table.aliases, alias)
+		table.versions = append(table.versions, constraints)
+	}
+	return table, nil
+}
+
+// Resolve returns the resource a request is redirected to by the first
+// matching alias, and whether one matched. Aliases with a version range
+// only match requests for an exact version within the range.
+func (a *AliasTable) Resolve(catalog, kind, name, requestedVersion string) (config.ResourceName, bool) {
+	for i, alias := range a.aliases {
+		if alias.Name != name ||
+			(alias.Catalog != "" && alias.Catalog != catalog) ||
+			(alias.Kind != "" && !strings.EqualFold(alias.Kind, kind)) {
+			continue
+		}
+
+		if a.versions[i] != nil {
+			requested, err := version.NewVersion(requestedVersion)
+			if err != nil || !a.versions[i].Check(requested) {
+				continue
+			}
+		}
+
+		resolved := config.ResourceName{Catalog: catalog, Kind: kind, Name: name}
+		if alias.To.Catalog != "" {
+			resolved.Catalog = alias.To.Catalog
+		}
+		if alias.To.Kind != "" {
+			resolved.Kind = alias.To.Kind
+		}
+		if alias.To.Name != "" {
+			resolved.Name = alias.To.Name
+		}
+		return resolved, true
+	}
+	return config.ResourceName{}, false
+}
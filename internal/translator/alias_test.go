@@ -0,0 +1,58 @@
+package translator
+
+import (
+	"tekton-hub-proxy/internal/config"
+	"testing"
+)
+
+func TestAliasTable_Resolve(t *testing.T) {
+	aliases, err := NewAliasTable([]config.ResourceAlias{
+		{Catalog: "tekton", Kind: "task", Name: "git-clone", Versions: ">=1.0", To: config.ResourceName{Name: "git-clone-v1"}},
+		{Name: "kaniko-build", To: config.ResourceName{Catalog: "community", Name: "kaniko"}},
+	}, NewVersionTranslator())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		catalog  string
+		kind     string
+		resource string
+		version  string
+		expected string
+	}{
+		{name: "version in range", catalog: "tekton", kind: "task", resource: "git-clone", version: "1.0", expected: "tekton/task/git-clone-v1"},
+		{name: "version below range", catalog: "tekton", kind: "task", resource: "git-clone", version: "0.9"},
+		{name: "latest skips ranged alias", catalog: "tekton", kind: "task", resource: "git-clone", version: ""},
+		{name: "other catalog", catalog: "community", kind: "task", resource: "git-clone", version: "1.0"},
+		{name: "any catalog and kind", catalog: "tekton", kind: "Task", resource: "kaniko-build", expected: "community/Task/kaniko"},
+		{name: "no alias", catalog: "tekton", kind: "task", resource: "buildah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, ok := aliases.Resolve(tt.catalog, tt.kind, tt.resource, tt.version)
+			got := ""
+			if ok {
+				got = resolved.Catalog + "/" + resolved.Kind + "/" + resolved.Name
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewAliasTable_Invalid(t *testing.T) {
+	invalid := [][]config.ResourceAlias{
+		{{To: config.ResourceName{Name: "git-clone-v1"}}},
+		{{Name: "git-clone"}},
+		{{Name: "git-clone", Versions: ">=main", To: config.ResourceName{Name: "git-clone-v1"}}},
+	}
+	for _, aliases := range invalid {
+		if _, err := NewAliasTable(aliases, NewVersionTranslator()); err == nil {
+			t.Errorf("expected error for %+v", aliases)
+		}
+	}
+}
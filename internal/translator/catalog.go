@@ -1,33 +1,128 @@
 package translator
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+
 	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/metrics"
+	"tekton-hub-proxy/internal/models"
+)
+
+// ErrUnknownCatalog is returned for catalogs without a mapping when
+// catalogs are strict.
+var ErrUnknownCatalog = errors.New("unknown catalog")
+
+// catalogPassthroughs counts catalog names used as is for lack of a mapping.
+var catalogPassthroughs = metrics.NewCounterVec(
+	"tekton_hub_proxy_catalog_passthrough_total",
+	"Catalog lookups without a mapping, passed through unchanged.",
+	"direction", "catalog",
 )
 
 type CatalogTranslator struct {
 	mappings            map[string]string
 	reverseMappings     map[string]string
+	targets             map[string][]string
+	kindTargets         map[string][]string
+	patterns            []*catalogPattern
+	precedence          map[string]int
+	targetOrder         []string
 	catalogMappingArray []config.CatalogMapping
+	overrides           map[string]config.CatalogMetadata
+	kinds               *KindTable
 }
 
-func NewCatalogTranslator(catalogMappings []config.CatalogMapping) *CatalogTranslator {
+func NewCatalogTranslator(catalogMappings []config.CatalogMapping, kinds *KindTable) *CatalogTranslator {
 	// Convert array to maps for fast lookup
 	mappings := make(map[string]string)
 	reverseMappings := make(map[string]string)
+	targets := make(map[string][]string)
+	kindTargets := make(map[string][]string)
+	precedence := make(map[string]int)
+	overrides := make(map[string]config.CatalogMetadata)
+	var patterns []*catalogPattern
+	var targetOrder []string
 
 	for _, mapping := range catalogMappings {
-		mappings[mapping.TektonHub] = mapping.ArtifactHub
-		reverseMappings[mapping.ArtifactHub] = mapping.TektonHub
+		if mapping.Pattern != "" {
+			pattern, err := newCatalogPattern(mapping)
+			if err != nil {
+				logrus.WithError(err).WithField("catalog", mapping.TektonHub).Error("❌ Invalid catalog pattern, ignoring mapping")
+				continue
+			}
+			if pattern.reverse == "" {
+				logrus.WithField("catalog", mapping.TektonHub).Warn("⚠️  Catalog pattern can't be reversed, its resources keep their Artifact Hub catalog names")
+			}
+			patterns = append(patterns, pattern)
+			continue
+		}
+
+		if mapping.Catalog != (config.CatalogMetadata{}) {
+			overrides[mapping.TektonHub] = mapping.Catalog
+		}
+		// Kind scoped mappings are keyed by catalog and kind, others by
+		// catalog and an empty kind. Only unscoped mappings translate the
+		// catalog for every kind.
+		key := kindKey(mapping.TektonHub, mapping.Kind)
+		for i, target := range mapping.AllTargets() {
+			if _, exists := mappings[mapping.TektonHub]; i == 0 && !exists && mapping.Kind == "" {
+				mappings[mapping.TektonHub] = target.ArtifactHub
+			}
+			if _, exists := reverseMappings[target.ArtifactHub]; !exists {
+				targetOrder = append(targetOrder, target.ArtifactHub)
+			}
+			if !slices.Contains(targets[mapping.TektonHub], target.ArtifactHub) {
+				targets[mapping.TektonHub] = append(targets[mapping.TektonHub], target.ArtifactHub)
+			}
+			kindTargets[key] = append(kindTargets[key], target.ArtifactHub)
+			reverseMappings[target.ArtifactHub] = mapping.TektonHub
+			precedence[target.ArtifactHub] = i
+		}
 	}
 
 	return &CatalogTranslator{
 		mappings:            mappings,
 		reverseMappings:     reverseMappings,
+		targets:             targets,
+		kindTargets:         kindTargets,
+		patterns:            patterns,
+		precedence:          precedence,
+		targetOrder:         targetOrder,
 		catalogMappingArray: catalogMappings,
+		overrides:           overrides,
+		kinds:               kinds,
+	}
+}
+
+// ValidateCatalogMappings checks the kinds and patterns of the mappings.
+// Catalogs matched by patterns are only known at request time, so pattern
+// mappings can't have dedicated backends.
+func ValidateCatalogMappings(catalogMappings []config.CatalogMapping, kinds *KindTable) error {
+	for _, mapping := range catalogMappings {
+		if mapping.Kind != "" {
+			if _, err := kinds.RepoKind(mapping.Kind); err != nil {
+				return fmt.Errorf("catalog %q: %w", mapping.TektonHub, err)
+			}
+		}
+		if mapping.Pattern == "" {
+			continue
+		}
+		if _, err := newCatalogPattern(mapping); err != nil {
+			return fmt.Errorf("catalog %q: %w", mapping.TektonHub, err)
+		}
+		for _, target := range mapping.AllTargets() {
+			if target.BackendName() != config.BackendArtifactHub || target.BaseURL != "" {
+				return fmt.Errorf("catalog %q: pattern mappings are served by the default Artifact Hub only", mapping.TektonHub)
+			}
+		}
 	}
+	return nil
 }
 
 func (c *CatalogTranslator) TektonToArtifactHub(tektonCatalog string) (string, error) {
@@ -48,12 +143,27 @@ func (c *CatalogTranslator) TektonToArtifactHub(tektonCatalog string) (string, e
 		return artifactHubCatalog, nil
 	}
 
+	for _, pattern := range c.patterns {
+		if targets, ok := pattern.forward(tektonCatalog); ok {
+			logrus.WithFields(logrus.Fields{
+				"translation_type":    "catalog",
+				"direction":           "tekton_to_artifacthub",
+				"tekton_catalog":      tektonCatalog,
+				"artifacthub_catalog": targets[0],
+				"pattern":             pattern.source,
+				"status":              "mapped",
+			}).Debug("✅ Catalog pattern matched")
+			return targets[0], nil
+		}
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"translation_type": "catalog",
 		"direction":        "tekton_to_artifacthub",
 		"tekton_catalog":   tektonCatalog,
 		"status":           "passthrough",
 	}).Debug("⚠️  No catalog mapping found, using original name")
+	catalogPassthroughs.Inc("tekton_to_artifacthub", tektonCatalog)
 	return tektonCatalog, nil
 }
 
@@ -75,12 +185,24 @@ func (c *CatalogTranslator) ArtifactHubToTekton(artifactHubCatalog string) (stri
 		return tektonCatalog, nil
 	}
 
+	if tektonCatalog, _, ok := c.backward(artifactHubCatalog); ok {
+		logrus.WithFields(logrus.Fields{
+			"translation_type":    "catalog",
+			"direction":           "artifacthub_to_tekton",
+			"artifacthub_catalog": artifactHubCatalog,
+			"tekton_catalog":      tektonCatalog,
+			"status":              "mapped",
+		}).Debug("✅ Reverse catalog pattern matched")
+		return tektonCatalog, nil
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"translation_type":    "catalog",
 		"direction":           "artifacthub_to_tekton",
 		"artifacthub_catalog": artifactHubCatalog,
 		"status":              "passthrough",
 	}).Debug("⚠️  No reverse catalog mapping found, using original name")
+	catalogPassthroughs.Inc("artifacthub_to_tekton", artifactHubCatalog)
 	return artifactHubCatalog, nil
 }
 
@@ -88,6 +210,175 @@ func (c *CatalogTranslator) GetAvailableMappings() map[string]string {
 	return c.mappings
 }
 
-func (c *CatalogTranslator) KindToRepoKind(kind string) string {
-	return fmt.Sprintf("tekton-%s", kind)
+// Mappings returns the catalog mappings in configuration order.
+func (c *CatalogTranslator) Mappings() []config.CatalogMapping {
+	return c.catalogMappingArray
+}
+
+// Catalog describes a Tekton catalog from its repository, which may be nil,
+// and the configured overrides. The ID derives from the Tekton catalog name
+// so it is the same on every call and for every target of the catalog.
+func (c *CatalogTranslator) Catalog(tektonCatalog string, repository *models.ArtifactHubRepository) models.TektonHubCatalog {
+	catalog := models.TektonHubCatalog{
+		ID:       hashID(tektonCatalog, 1000),
+		Name:     tektonCatalog,
+		Provider: "github",
+		Type:     "community",
+	}
+
+	if repository != nil {
+		catalog.DisplayName = repository.DisplayName
+		catalog.URL = repository.URL
+		catalog.VerifiedPublisher = repository.VerifiedPublisher
+		if repository.Official {
+			catalog.Type = "official"
+		}
+		if provider := providerFromURL(repository.URL); provider != "" {
+			catalog.Provider = provider
+		}
+	}
+
+	override := c.overrides[tektonCatalog]
+	if override.ID != 0 {
+		catalog.ID = override.ID
+	}
+	if override.DisplayName != "" {
+		catalog.DisplayName = override.DisplayName
+	}
+	if override.Provider != "" {
+		catalog.Provider = override.Provider
+	}
+	if override.Type != "" {
+		catalog.Type = override.Type
+	}
+	if override.URL != "" {
+		catalog.URL = override.URL
+	}
+	return catalog
+}
+
+// providerFromURL names the git provider hosting a repository URL, e.g.
+// "github" for https://github.com/tektoncd/catalog.
+func providerFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	for _, provider := range []string{"github", "gitlab", "bitbucket"} {
+		if strings.HasPrefix(host, provider+".") {
+			return provider
+		}
+	}
+	return host
+}
+
+// Targets returns the target catalogs of a Tekton catalog for every kind in
+// lookup order. Unmapped catalogs resolve to themselves.
+func (c *CatalogTranslator) Targets(tektonCatalog string) []string {
+	// Targets of every kind can't miss a kind
+	targets, _ := c.KindTargets(tektonCatalog, "")
+	return targets
+}
+
+// KindTargets returns the target catalogs of a Tekton catalog for one kind
+// in lookup order, all kinds when kind is empty. Mappings scoped to the kind
+// win over unscoped ones, literal mappings over patterns. Catalogs mapped
+// for other kinds only return ErrUnknownCatalog.
+func (c *CatalogTranslator) KindTargets(tektonCatalog, kind string) ([]string, error) {
+	if targets, exists := c.targets[tektonCatalog]; exists {
+		if kind == "" {
+			return targets, nil
+		}
+		if targets, exists := c.kindTargets[kindKey(tektonCatalog, kind)]; exists {
+			return targets, nil
+		}
+		if targets, exists := c.kindTargets[kindKey(tektonCatalog, "")]; exists {
+			return targets, nil
+		}
+		return nil, fmt.Errorf("catalog %q has no mapping for kind %q: %w", tektonCatalog, kind, ErrUnknownCatalog)
+	}
+
+	for _, pattern := range c.patterns {
+		if !pattern.appliesTo(kind) {
+			continue
+		}
+		if targets, ok := pattern.forward(tektonCatalog); ok {
+			return targets, nil
+		}
+	}
+
+	artifactHubCatalog, _ := c.TektonToArtifactHub(tektonCatalog)
+	return []string{artifactHubCatalog}, nil
+}
+
+// TargetCatalogs returns every literally mapped target catalog in
+// configuration order. Catalogs matched by patterns can't be listed.
+func (c *CatalogTranslator) TargetCatalogs() []string {
+	return c.targetOrder
+}
+
+// HasPatterns reports whether catalogs are mapped by patterns, in which case
+// TargetCatalogs doesn't list every mapped catalog.
+func (c *CatalogTranslator) HasPatterns() bool {
+	return len(c.patterns) > 0
+}
+
+// CheckCatalog returns ErrUnknownCatalog for Tekton catalogs neither
+// mapped nor matched by a pattern.
+func (c *CatalogTranslator) CheckCatalog(tektonCatalog string) error {
+	if _, exists := c.targets[tektonCatalog]; exists {
+		return nil
+	}
+	for _, pattern := range c.patterns {
+		if _, ok := pattern.forward(tektonCatalog); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("catalog %q: %w", tektonCatalog, ErrUnknownCatalog)
+}
+
+// IsMapped reports whether an Artifact Hub catalog is the target of a
+// mapping or pattern.
+func (c *CatalogTranslator) IsMapped(artifactHubCatalog string) bool {
+	if _, exists := c.reverseMappings[artifactHubCatalog]; exists {
+		return true
+	}
+	_, _, ok := c.backward(artifactHubCatalog)
+	return ok
+}
+
+// Precedence returns the position of a target catalog within its mapping,
+// lower values win when several targets have the same resource.
+func (c *CatalogTranslator) Precedence(artifactHubCatalog string) int {
+	if precedence, exists := c.precedence[artifactHubCatalog]; exists {
+		return precedence
+	}
+	_, precedence, _ := c.backward(artifactHubCatalog)
+	return precedence
+}
+
+// backward maps an Artifact Hub catalog back through the patterns.
+func (c *CatalogTranslator) backward(artifactHubCatalog string) (string, int, bool) {
+	for _, pattern := range c.patterns {
+		if tektonCatalog, precedence, ok := pattern.backward(artifactHubCatalog); ok {
+			return tektonCatalog, precedence, true
+		}
+	}
+	return "", 0, false
+}
+
+func kindKey(tektonCatalog, kind string) string {
+	return tektonCatalog + "/" + strings.ToLower(kind)
+}
+
+// KindToRepoKind returns the Artifact Hub repository kind of a Tekton kind,
+// or ErrUnknownKind.
+func (c *CatalogTranslator) KindToRepoKind(kind string) (string, error) {
+	return c.kinds.RepoKind(kind)
+}
+
+// Kinds returns the kind table used by the translator.
+func (c *CatalogTranslator) Kinds() *KindTable {
+	return c.kinds
 }
\ No newline at end of file
@@ -0,0 +1,265 @@
+package translator
+
+import (
+	"errors"
+	"reflect"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"testing"
+)
+
+func TestCatalogTranslator_Targets(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "community", ArtifactHub: "tekton-catalog-community"},
+		{
+			TektonHub: "tekton",
+			Targets: []config.CatalogTarget{
+				{ArtifactHub: "overrides", Backend: config.BackendFilesystem, Path: "/srv/catalog"},
+				{ArtifactHub: "acme-tasks", BaseURL: "https://artifacthub.acme.internal"},
+				{ArtifactHub: "tekton-catalog-tasks"},
+			},
+		},
+		{TektonHub: "internal", Backend: config.BackendFilesystem, Path: "/srv/internal"},
+	}, DefaultKindTable())
+
+	tests := []struct {
+		name     string
+		catalog  string
+		expected []string
+	}{
+		{name: "inline target", catalog: "community", expected: []string{"tekton-catalog-community"}},
+		{name: "ordered targets", catalog: "tekton", expected: []string{"overrides", "acme-tasks", "tekton-catalog-tasks"}},
+		{name: "target defaults to tekton name", catalog: "internal", expected: []string{"internal"}},
+		{name: "unmapped passthrough", catalog: "unknown", expected: []string{"unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translator.Targets(tt.catalog); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	primary, _ := translator.TektonToArtifactHub("tekton")
+	if primary != "overrides" {
+		t.Errorf("expected first target as primary mapping, got %q", primary)
+	}
+
+	for _, target := range []string{"overrides", "acme-tasks", "tekton-catalog-tasks"} {
+		if catalog, _ := translator.ArtifactHubToTekton(target); catalog != "tekton" {
+			t.Errorf("expected %s to map back to tekton, got %q", target, catalog)
+		}
+	}
+
+	if translator.Precedence("overrides") >= translator.Precedence("tekton-catalog-tasks") {
+		t.Error("expected earlier targets to take precedence")
+	}
+
+	expectedTargets := []string{"tekton-catalog-community", "overrides", "acme-tasks", "tekton-catalog-tasks", "internal"}
+	if got := translator.TargetCatalogs(); !reflect.DeepEqual(got, expectedTargets) {
+		t.Errorf("expected target catalogs %v, got %v", expectedTargets, got)
+	}
+}
+
+func TestCatalogTranslator_Catalog(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "acme", ArtifactHub: "acme-tasks", Catalog: config.CatalogMetadata{ID: 42, Type: "partner", URL: "https://acme.example/catalog"}},
+	}, DefaultKindTable())
+
+	repository := &models.ArtifactHubRepository{
+		Name:              "tekton-catalog-tasks",
+		DisplayName:       "Tekton Catalog Tasks",
+		URL:               "https://github.com/tektoncd/catalog",
+		Official:          true,
+		VerifiedPublisher: true,
+	}
+
+	tests := []struct {
+		name       string
+		catalog    string
+		repository *models.ArtifactHubRepository
+		expected   models.TektonHubCatalog
+	}{
+		{
+			name:       "from repository",
+			catalog:    "tekton",
+			repository: repository,
+			expected: models.TektonHubCatalog{
+				ID: hashID("tekton", 1000), Name: "tekton", DisplayName: "Tekton Catalog Tasks",
+				Provider: "github", Type: "official", URL: "https://github.com/tektoncd/catalog", VerifiedPublisher: true,
+			},
+		},
+		{
+			name:    "without repository",
+			catalog: "tekton",
+			expected: models.TektonHubCatalog{
+				ID: hashID("tekton", 1000), Name: "tekton", Provider: "github", Type: "community",
+			},
+		},
+		{
+			name:       "overrides",
+			catalog:    "acme",
+			repository: &models.ArtifactHubRepository{Name: "acme-tasks", URL: "https://gitlab.com/acme/tasks"},
+			expected: models.TektonHubCatalog{
+				ID: 42, Name: "acme", Provider: "gitlab", Type: "partner", URL: "https://acme.example/catalog",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translator.Catalog(tt.catalog, tt.repository); got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCatalogTranslator_KindTargets_Scoped(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", Kind: "task", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "tekton", Kind: "pipeline", ArtifactHub: "tekton-catalog-pipelines"},
+	}, DefaultKindTable())
+
+	if _, err := translator.KindTargets("tekton", "stepaction"); !errors.Is(err, ErrUnknownCatalog) {
+		t.Errorf("expected ErrUnknownCatalog for an unmapped kind, got %v", err)
+	}
+	if targets, err := translator.KindTargets("tekton", "pipeline"); err != nil || !reflect.DeepEqual(targets, []string{"tekton-catalog-pipelines"}) {
+		t.Errorf("expected the pipeline target, got %v, %v", targets, err)
+	}
+	// Scoped mappings don't translate the catalog for other kinds
+	if _, exists := translator.GetAvailableMappings()["tekton"]; exists {
+		t.Errorf("expected no unscoped mapping, got %v", translator.GetAvailableMappings())
+	}
+}
+
+func TestCatalogTranslator_KindTargets(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", Kind: "task", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "tekton", Kind: "pipeline", ArtifactHub: "tekton-catalog-pipelines"},
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-stepactions"},
+	}, DefaultKindTable())
+
+	tests := []struct {
+		name     string
+		kind     string
+		expected []string
+	}{
+		{name: "task", kind: "task", expected: []string{"tekton-catalog-tasks"}},
+		{name: "kind ignores case", kind: "Pipeline", expected: []string{"tekton-catalog-pipelines"}},
+		{name: "unscoped fallback", kind: "stepaction", expected: []string{"tekton-catalog-stepactions"}},
+		{name: "every kind", kind: "", expected: []string{"tekton-catalog-tasks", "tekton-catalog-pipelines", "tekton-catalog-stepactions"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := translator.KindTargets("tekton", tt.kind)
+			if err != nil || !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v, %v", tt.expected, got, err)
+			}
+		})
+	}
+
+	for _, target := range translator.Targets("tekton") {
+		if catalog, _ := translator.ArtifactHubToTekton(target); catalog != "tekton" {
+			t.Errorf("expected %s to map back to tekton, got %q", target, catalog)
+		}
+	}
+}
+
+func TestCatalogTranslator_Patterns(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "team-*", Pattern: config.PatternGlob, ArtifactHub: "acme-team-*"},
+		{TektonHub: `org-(\w+)-(\w+)`, Pattern: config.PatternRegex, ArtifactHub: "${2}-${1}-tasks"},
+		{TektonHub: `dept-(a|b)`, Pattern: config.PatternRegex, ArtifactHub: "dept-$1"},
+		{TektonHub: `svc-\d+`, Pattern: config.PatternRegex, ArtifactHub: "shared-services"},
+	}, DefaultKindTable())
+
+	tests := []struct {
+		name        string
+		tekton      string
+		artifactHub string
+		reversible  bool
+	}{
+		{name: "literal wins", tekton: "tekton", artifactHub: "tekton-catalog-tasks", reversible: true},
+		{name: "glob", tekton: "team-platform", artifactHub: "acme-team-platform", reversible: true},
+		{name: "regex reordering captures", tekton: "org-acme-ci", artifactHub: "ci-acme-tasks", reversible: true},
+		{name: "alternation in capture", tekton: "dept-b", artifactHub: "dept-b", reversible: true},
+		{name: "many to one can't be reversed", tekton: "svc-12", artifactHub: "shared-services"},
+		{name: "unmatched passthrough", tekton: "other", artifactHub: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := translator.TektonToArtifactHub(tt.tekton)
+			if got != tt.artifactHub {
+				t.Errorf("expected %q, got %q", tt.artifactHub, got)
+			}
+			if translator.IsMapped(tt.artifactHub) != tt.reversible {
+				t.Errorf("expected mapped %v for %q", tt.reversible, tt.artifactHub)
+			}
+			if tt.reversible {
+				if back, _ := translator.ArtifactHubToTekton(tt.artifactHub); back != tt.tekton {
+					t.Errorf("expected %q to map back to %q, got %q", tt.artifactHub, tt.tekton, back)
+				}
+			}
+		})
+	}
+
+	if translator.IsMapped("acme-team-") {
+		t.Error("expected glob captures to be non-empty")
+	}
+	if !translator.HasPatterns() {
+		t.Error("expected patterns")
+	}
+}
+
+func TestValidateCatalogMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping config.CatalogMapping
+		valid   bool
+	}{
+		{name: "glob", mapping: config.CatalogMapping{TektonHub: "team-*", Pattern: config.PatternGlob, ArtifactHub: "acme-*"}, valid: true},
+		{name: "unknown kind", mapping: config.CatalogMapping{TektonHub: "tekton", Kind: "workflow"}},
+		{name: "unknown pattern type", mapping: config.CatalogMapping{TektonHub: "team-*", Pattern: "wildcard"}},
+		{name: "invalid regex", mapping: config.CatalogMapping{TektonHub: "team-(", Pattern: config.PatternRegex}},
+		{name: "missing capture", mapping: config.CatalogMapping{TektonHub: "team-(.+)", Pattern: config.PatternRegex, ArtifactHub: "acme-$2"}},
+		{name: "dedicated backend", mapping: config.CatalogMapping{TektonHub: "team-*", Pattern: config.PatternGlob, Backend: config.BackendFilesystem, Path: "/srv"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCatalogMappings([]config.CatalogMapping{tt.mapping}, DefaultKindTable())
+			if (err == nil) != tt.valid {
+				t.Errorf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestCatalogTranslator_CheckCatalog(t *testing.T) {
+	translator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+		{TektonHub: "team-*", Pattern: config.PatternGlob, ArtifactHub: "acme-team-*"},
+	}, DefaultKindTable())
+
+	for _, catalog := range []string{"tekton", "team-ci"} {
+		if err := translator.CheckCatalog(catalog); err != nil {
+			t.Errorf("expected %s to be known, got %v", catalog, err)
+		}
+	}
+
+	if err := translator.CheckCatalog("tekton-typo"); !errors.Is(err, ErrUnknownCatalog) {
+		t.Errorf("expected ErrUnknownCatalog, got %v", err)
+	}
+
+	before := catalogPassthroughs.Value("tekton_to_artifacthub", "tekton-typo")
+	translator.Targets("tekton-typo")
+	if got := catalogPassthroughs.Value("tekton_to_artifacthub", "tekton-typo"); got != before+1 {
+		t.Errorf("expected passthrough to be counted, got %v", got-before)
+	}
+}
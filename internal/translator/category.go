@@ -0,0 +1,92 @@
+package translator
+
+import (
+	"strings"
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CategoryTable maps keywords and tekton.dev/categories annotations onto the
+// Tekton Hub category taxonomy.
+type CategoryTable struct {
+	categories []models.TektonHubCategory
+	byName     map[string]int
+	byKeyword  map[string][]int
+}
+
+// NewCategoryTable builds the taxonomy. Categories without an ID get one
+// derived from their name so it survives reordering the configuration.
+func NewCategoryTable(categories []config.CategoryMapping) *CategoryTable {
+	table := &CategoryTable{
+		byName:    make(map[string]int),
+		byKeyword: make(map[string][]int),
+	}
+	for i, category := range categories {
+		id := category.ID
+		if id == 0 {
+			id = hashID(category.Name, 1000)
+		}
+		table.categories = append(table.categories, models.TektonHubCategory{ID: id, Name: category.Name})
+
+		name := strings.ToLower(category.Name)
+		table.byName[name] = i
+		table.byKeyword[name] = append(table.byKeyword[name], i)
+		for _, keyword := range category.Keywords {
+			keyword = strings.ToLower(keyword)
+			table.byKeyword[keyword] = append(table.byKeyword[keyword], i)
+		}
+	}
+	return table
+}
+
+// DefaultCategoryTable returns the Tekton Hub category list.
+func DefaultCategoryTable() *CategoryTable {
+	return NewCategoryTable(config.DefaultCategories())
+}
+
+// Categories returns the taxonomy in configuration order.
+func (c *CategoryTable) Categories() []models.TektonHubCategory {
+	return c.categories
+}
+
+// Lookup returns the category with the given name, ignoring case.
+func (c *CategoryTable) Lookup(name string) (models.TektonHubCategory, bool) {
+	i, exists := c.byName[strings.ToLower(strings.TrimSpace(name))]
+	if !exists {
+		return models.TektonHubCategory{}, false
+	}
+	return c.categories[i], true
+}
+
+// Classify returns the categories named by the annotation, a comma separated
+// list of category names, and the categories of the keywords, in taxonomy
+// order.
+func (c *CategoryTable) Classify(annotation string, keywords []string) []models.TektonHubCategory {
+	matched := make(map[int]bool)
+	for _, name := range strings.Split(annotation, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		i, exists := c.byName[strings.ToLower(strings.TrimSpace(name))]
+		if !exists {
+			logrus.WithField("category", name).Debug("Category not in taxonomy, ignoring")
+			continue
+		}
+		matched[i] = true
+	}
+	for _, keyword := range keywords {
+		for _, i := range c.byKeyword[strings.ToLower(strings.TrimSpace(keyword))] {
+			matched[i] = true
+		}
+	}
+
+	var categories []models.TektonHubCategory
+	for i, category := range c.categories {
+		if matched[i] {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
@@ -0,0 +1,47 @@
+package translator
+
+import (
+	"tekton-hub-proxy/internal/config"
+	"testing"
+)
+
+func TestCategoryTable_Classify(t *testing.T) {
+	categories := NewCategoryTable([]config.CategoryMapping{
+		{ID: 2, Name: "Build Tools", Keywords: []string{"build", "kaniko"}},
+		{Name: "Git", Keywords: []string{"git", "scm"}},
+		{ID: 18, Name: "Security", Keywords: []string{"scan"}},
+	})
+
+	tests := []struct {
+		name       string
+		annotation string
+		keywords   []string
+		expected   []string
+	}{
+		{name: "annotation", annotation: "security, build tools", expected: []string{"Build Tools", "Security"}},
+		{name: "keywords", keywords: []string{"SCM", "kaniko", "unrelated"}, expected: []string{"Build Tools", "Git"}},
+		{name: "category name as keyword", keywords: []string{"git"}, expected: []string{"Git"}},
+		{name: "annotation and keywords deduplicated", annotation: "Git", keywords: []string{"git", "scan"}, expected: []string{"Git", "Security"}},
+		{name: "unknown category", annotation: "Fun", keywords: []string{"misc"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := categories.Classify(tt.annotation, tt.keywords)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, result)
+			}
+			for i, category := range result {
+				if category.Name != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, result)
+				}
+			}
+		})
+	}
+
+	buildTools, _ := categories.Lookup("build tools")
+	git, _ := categories.Lookup("Git")
+	if buildTools.ID != 2 || git.ID == 0 || git.ID != NewCategoryTable([]config.CategoryMapping{{Name: "Git"}}).Categories()[0].ID {
+		t.Errorf("expected configured and stable derived IDs, got %d and %d", buildTools.ID, git.ID)
+	}
+}
@@ -0,0 +1,153 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/sirupsen/logrus"
+)
+
+// LatestVersion requests the newest release of a resource.
+const LatestVersion = "latest"
+
+var (
+	// ErrInvalidVersionConstraint is returned for requested versions that are
+	// neither a version nor a constraint.
+	ErrInvalidVersionConstraint = errors.New("invalid version constraint")
+	// ErrNoMatchingVersion is returned when no available version satisfies
+	// the constraint.
+	ErrNoMatchingVersion = errors.New("no matching version")
+)
+
+var (
+	wildcardRegex = regexp.MustCompile(`^v?(\d+)(?:\.(\d+|[xX*]))?(?:\.([xX*]))?$`)
+	hyphenRegex   = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+)
+
+// IsExactVersion reports whether the requested version names one version
+// rather than a constraint.
+func (v *VersionTranslator) IsExactVersion(requested string) bool {
+	_, err := version.NewVersion(requested)
+	return err == nil
+}
+
+// ResolveVersion returns the highest available version satisfying the
+// requested constraint. Besides "latest" it accepts comparisons joined by
+// commas (">=0.7, <1.0"), wildcards ("0.9.x"), tilde ("~0.9.1"), caret
+// ("^0.9") and hyphen ranges ("0.7 - 0.9"). Prereleases only match
+// constraints naming a prerelease.
+func (v *VersionTranslator) ResolveVersion(requested string, available []string) (string, error) {
+	logrus.WithFields(logrus.Fields{
+		"translation_type": "version",
+		"direction":        "resolve",
+		"input":            requested,
+		"available":        available,
+	}).Debug("🔄 Translation request")
+
+	var match func(*version.Version) bool
+	if strings.EqualFold(strings.TrimSpace(requested), LatestVersion) {
+		match = func(ver *version.Version) bool { return ver.Prerelease() == "" }
+	} else {
+		constraints, err := v.ParseConstraint(requested)
+		if err != nil {
+			return "", err
+		}
+		match = constraints.Check
+	}
+
+	var best *version.Version
+	var resolved string
+	for _, candidate := range available {
+		ver, err := version.NewVersion(candidate)
+		if err != nil || !match(ver) {
+			continue
+		}
+		if best == nil || ver.GreaterThan(best) {
+			best, resolved = ver, candidate
+		}
+	}
+
+	if best == nil {
+		return "", fmt.Errorf("%q: %w", requested, ErrNoMatchingVersion)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"translation_type": "version",
+		"direction":        "resolve",
+		"input":            requested,
+		"output":           resolved,
+		"status":           "resolved",
+	}).Debug("✅ Resolved version constraint")
+	return resolved, nil
+}
+
+// ParseConstraint parses a version constraint in the forms accepted for
+// requested versions.
+func (v *VersionTranslator) ParseConstraint(requested string) (version.Constraints, error) {
+	var expanded []string
+	for _, part := range strings.Split(requested, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("%q: %w", requested, ErrInvalidVersionConstraint)
+		}
+		parts, err := expandConstraint(part)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", requested, err)
+		}
+		expanded = append(expanded, parts...)
+	}
+
+	constraints, err := version.NewConstraint(strings.Join(expanded, ", "))
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", requested, ErrInvalidVersionConstraint)
+	}
+	return constraints, nil
+}
+
+// expandConstraint rewrites the constraint forms go-version lacks into
+// plain comparisons.
+func expandConstraint(part string) ([]string, error) {
+	if m := hyphenRegex.FindStringSubmatch(part); m != nil {
+		return []string{">= " + m[1], "<= " + m[2]}, nil
+	}
+
+	if m := wildcardRegex.FindStringSubmatch(part); m != nil && (isWildcard(m[2]) || isWildcard(m[3]) || m[2] == "") {
+		major, _ := strconv.Atoi(m[1])
+		if m[2] == "" || isWildcard(m[2]) {
+			return []string{fmt.Sprintf(">= %d.0.0", major), fmt.Sprintf("< %d.0.0", major+1)}, nil
+		}
+		minor, _ := strconv.Atoi(m[2])
+		return []string{fmt.Sprintf(">= %d.%d.0", major, minor), fmt.Sprintf("< %d.%d.0", major, minor+1)}, nil
+	}
+
+	switch {
+	case strings.HasPrefix(part, "^"):
+		lower, err := version.NewVersion(strings.TrimSpace(part[1:]))
+		if err != nil {
+			return nil, ErrInvalidVersionConstraint
+		}
+		segments := lower.Segments()
+		upper := fmt.Sprintf("%d.0.0", segments[0]+1)
+		if segments[0] == 0 {
+			upper = fmt.Sprintf("0.%d.0", segments[1]+1)
+		}
+		return []string{">= " + lower.String(), "< " + upper}, nil
+	case strings.HasPrefix(part, "~") && !strings.HasPrefix(part, "~>"):
+		lower, err := version.NewVersion(strings.TrimSpace(part[1:]))
+		if err != nil {
+			return nil, ErrInvalidVersionConstraint
+		}
+		segments := lower.Segments()
+		return []string{">= " + lower.String(), fmt.Sprintf("< %d.%d.0", segments[0], segments[1]+1)}, nil
+	}
+
+	return []string{part}, nil
+}
+
+func isWildcard(segment string) bool {
+	return segment == "x" || segment == "X" || segment == "*"
+}
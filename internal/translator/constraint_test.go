@@ -0,0 +1,51 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersionTranslator_ResolveVersion(t *testing.T) {
+	translator := NewVersionTranslator()
+	available := []string{"0.7.0", "0.9.0", "0.9.2", "0.10.0", "1.0.0", "1.1.0-rc.1", "1.2.1"}
+
+	tests := []struct {
+		name      string
+		requested string
+		expected  string
+		err       error
+	}{
+		{name: "latest", requested: "latest", expected: "1.2.1"},
+		{name: "exact simplified", requested: "0.9", expected: "0.9.0"},
+		{name: "wildcard minor", requested: "0.9.x", expected: "0.9.2"},
+		{name: "wildcard major", requested: "0.*", expected: "0.10.0"},
+		{name: "range", requested: ">=0.7, <1.0", expected: "0.10.0"},
+		{name: "hyphen range", requested: "0.7 - 0.9.1", expected: "0.9.0"},
+		{name: "tilde", requested: "~0.9.1", expected: "0.9.2"},
+		{name: "pessimistic", requested: "~> 0.9.0", expected: "0.9.2"},
+		{name: "caret zero major", requested: "^0.9", expected: "0.9.2"},
+		{name: "caret", requested: "^1.0", expected: "1.2.1"},
+		{name: "prerelease excluded", requested: ">1.0, <1.2", err: ErrNoMatchingVersion},
+		{name: "no match", requested: ">=2.0", err: ErrNoMatchingVersion},
+		{name: "invalid", requested: "main", err: ErrInvalidVersionConstraint},
+		{name: "empty part", requested: ">=0.7,", err: ErrInvalidVersionConstraint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := translator.ResolveVersion(tt.requested, available)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("expected %v, got %v (%q)", tt.err, err, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
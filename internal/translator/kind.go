@@ -0,0 +1,116 @@
+package translator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tekton-hub-proxy/internal/config"
+)
+
+// ErrUnknownKind is returned for kinds missing from the kind table.
+var ErrUnknownKind = errors.New("unknown kind")
+
+// KindTable maps Tekton Hub kinds to Artifact Hub repository kinds and back.
+type KindTable struct {
+	kinds            []config.KindMapping
+	byName           map[string]config.KindMapping
+	byRepoKind       map[string]config.KindMapping
+	byRepositoryKind map[int]config.KindMapping
+}
+
+func NewKindTable(kinds []config.KindMapping) *KindTable {
+	table := &KindTable{
+		kinds:            kinds,
+		byName:           make(map[string]config.KindMapping),
+		byRepoKind:       make(map[string]config.KindMapping),
+		byRepositoryKind: make(map[int]config.KindMapping),
+	}
+	for _, kind := range kinds {
+		if kind.RepoKind == "" {
+			kind.RepoKind = "tekton-" + kind.Name
+		}
+		table.byName[strings.ToLower(kind.Name)] = kind
+		table.byRepoKind[kind.RepoKind] = kind
+		table.byRepositoryKind[kind.RepositoryKind] = kind
+	}
+	return table
+}
+
+// DefaultKindTable returns the table of the Tekton kinds known to Artifact Hub.
+func DefaultKindTable() *KindTable {
+	return NewKindTable(config.DefaultKinds())
+}
+
+// Names returns the Tekton Hub kinds in configuration order.
+func (k *KindTable) Names() []string {
+	names := make([]string, 0, len(k.kinds))
+	for _, kind := range k.kinds {
+		names = append(names, kind.Name)
+	}
+	return names
+}
+
+// RepositoryKinds returns the Artifact Hub repository kind IDs of all kinds.
+func (k *KindTable) RepositoryKinds() []int {
+	ids := make([]int, 0, len(k.kinds))
+	for _, kind := range k.kinds {
+		ids = append(ids, kind.RepositoryKind)
+	}
+	return ids
+}
+
+// RepoKind returns the repository kind name used in Artifact Hub package
+// URLs for a Tekton kind, e.g. "tekton-task" for "task".
+func (k *KindTable) RepoKind(name string) (string, error) {
+	kind, err := k.lookup(name)
+	if err != nil {
+		return "", err
+	}
+	return kind.RepoKind, nil
+}
+
+// RepositoryKind returns the Artifact Hub repository kind ID of a Tekton kind.
+func (k *KindTable) RepositoryKind(name string) (int, error) {
+	kind, err := k.lookup(name)
+	if err != nil {
+		return 0, err
+	}
+	return kind.RepositoryKind, nil
+}
+
+// FromRepositoryKind returns the Tekton kind of an Artifact Hub repository
+// kind ID.
+func (k *KindTable) FromRepositoryKind(id int) (string, error) {
+	kind, exists := k.byRepositoryKind[id]
+	if !exists {
+		return "", fmt.Errorf("repository kind %d: %w", id, ErrUnknownKind)
+	}
+	return kind.Name, nil
+}
+
+// RepoKindFromRepositoryKind returns the repository kind name of an Artifact
+// Hub repository kind ID.
+func (k *KindTable) RepoKindFromRepositoryKind(id int) (string, error) {
+	kind, exists := k.byRepositoryKind[id]
+	if !exists {
+		return "", fmt.Errorf("repository kind %d: %w", id, ErrUnknownKind)
+	}
+	return kind.RepoKind, nil
+}
+
+// FromRepoKind returns the Tekton kind of a repository kind name.
+func (k *KindTable) FromRepoKind(repoKind string) (string, error) {
+	kind, exists := k.byRepoKind[repoKind]
+	if !exists {
+		return "", fmt.Errorf("repository kind %q: %w", repoKind, ErrUnknownKind)
+	}
+	return kind.Name, nil
+}
+
+func (k *KindTable) lookup(name string) (config.KindMapping, error) {
+	kind, exists := k.byName[strings.ToLower(name)]
+	if !exists {
+		return config.KindMapping{}, fmt.Errorf("kind %q: %w", name, ErrUnknownKind)
+	}
+	return kind, nil
+}
@@ -0,0 +1,48 @@
+package translator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKindTable(t *testing.T) {
+	kinds := DefaultKindTable()
+
+	tests := []struct {
+		kind           string
+		repoKind       string
+		repositoryKind int
+	}{
+		{kind: "task", repoKind: "tekton-task", repositoryKind: 7},
+		{kind: "pipeline", repoKind: "tekton-pipeline", repositoryKind: 11},
+		{kind: "stepaction", repoKind: "tekton-stepaction", repositoryKind: 23},
+		{kind: "Task", repoKind: "tekton-task", repositoryKind: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			repoKind, err := kinds.RepoKind(tt.kind)
+			if err != nil || repoKind != tt.repoKind {
+				t.Errorf("expected repo kind %s, got %s (%v)", tt.repoKind, repoKind, err)
+			}
+			id, err := kinds.RepositoryKind(tt.kind)
+			if err != nil || id != tt.repositoryKind {
+				t.Errorf("expected repository kind %d, got %d (%v)", tt.repositoryKind, id, err)
+			}
+			if kind, _ := kinds.FromRepositoryKind(tt.repositoryKind); kind != strings.ToLower(tt.kind) {
+				t.Errorf("expected kind %s from repository kind, got %s", tt.kind, kind)
+			}
+			if kind, _ := kinds.FromRepoKind(tt.repoKind); kind != strings.ToLower(tt.kind) {
+				t.Errorf("expected kind %s from repo kind, got %s", tt.kind, kind)
+			}
+		})
+	}
+
+	if _, err := kinds.RepoKind("cluster-task"); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+	if _, err := kinds.FromRepositoryKind(12); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("expected ErrUnknownKind, got %v", err)
+	}
+}
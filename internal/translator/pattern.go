@@ -0,0 +1,225 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"tekton-hub-proxy/internal/config"
+)
+
+// templateRefRegex matches the capture references of a catalog name template.
+var templateRefRegex = regexp.MustCompile(`\$\$|\$(\d+)|\$\{(\d+)\}`)
+
+// catalogPattern maps the Tekton Hub catalogs matching a glob or regex to
+// Artifact Hub catalogs named by substituting the captures into templates.
+type catalogPattern struct {
+	source  string
+	kind    string
+	tekton  *regexp.Regexp
+	targets []patternTarget
+	// reverse rebuilds the Tekton Hub name from the captures, empty when
+	// the regex is more than literals and captures.
+	reverse string
+}
+
+// patternTarget is the template of one target of a pattern mapping.
+type patternTarget struct {
+	template string
+	// match recovers the captures from an Artifact Hub name, groups lists
+	// the capture each of its groups stands for.
+	match  *regexp.Regexp
+	groups []int
+}
+
+func newCatalogPattern(mapping config.CatalogMapping) (*catalogPattern, error) {
+	var expr, reverse string
+	var templates []string
+
+	switch mapping.Pattern {
+	case config.PatternGlob:
+		expr, reverse = globToRegex(mapping.TektonHub)
+		for _, target := range mapping.AllTargets() {
+			templates = append(templates, globToTemplate(target.ArtifactHub))
+		}
+	case config.PatternRegex:
+		expr = mapping.TektonHub
+		for _, target := range mapping.AllTargets() {
+			templates = append(templates, target.ArtifactHub)
+		}
+	default:
+		return nil, fmt.Errorf("unknown pattern type %q, expected %q or %q", mapping.Pattern, config.PatternGlob, config.PatternRegex)
+	}
+
+	tekton, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if mapping.Pattern == config.PatternRegex {
+		reverse = regexTemplate(expr)
+	}
+
+	pattern := &catalogPattern{
+		source:  mapping.TektonHub,
+		kind:    strings.ToLower(mapping.Kind),
+		tekton:  tekton,
+		reverse: reverse,
+	}
+	for _, template := range templates {
+		target, err := newPatternTarget(template, tekton.NumSubexp())
+		if err != nil {
+			return nil, err
+		}
+		pattern.targets = append(pattern.targets, target)
+	}
+	return pattern, nil
+}
+
+func newPatternTarget(template string, captures int) (patternTarget, error) {
+	target := patternTarget{template: template}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, m := range templateRefRegex.FindAllStringSubmatchIndex(template, -1) {
+		expr.WriteString(regexp.QuoteMeta(template[last:m[0]]))
+		last = m[1]
+
+		ref := template[m[0]:m[1]]
+		if ref == "$$" {
+			expr.WriteString(`\$`)
+			continue
+		}
+		group, _ := strconv.Atoi(strings.Trim(ref, "${}"))
+		if group == 0 || group > captures {
+			return target, fmt.Errorf("template %q references capture %d of %d", template, group, captures)
+		}
+		expr.WriteString("(.+?)")
+		target.groups = append(target.groups, group)
+	}
+	expr.WriteString(regexp.QuoteMeta(template[last:]))
+	expr.WriteString("$")
+
+	target.match = regexp.MustCompile(expr.String())
+	return target, nil
+}
+
+func (p *catalogPattern) appliesTo(kind string) bool {
+	return p.kind == "" || kind == "" || p.kind == strings.ToLower(kind)
+}
+
+// forward returns the Artifact Hub targets of a Tekton Hub catalog, or false
+// when the catalog doesn't match.
+func (p *catalogPattern) forward(tektonCatalog string) ([]string, bool) {
+	m := p.tekton.FindStringSubmatchIndex(tektonCatalog)
+	if m == nil {
+		return nil, false
+	}
+	targets := make([]string, len(p.targets))
+	for i, target := range p.targets {
+		targets[i] = string(p.tekton.ExpandString(nil, target.template, tektonCatalog, m))
+	}
+	return targets, true
+}
+
+// backward returns the Tekton Hub catalog an Artifact Hub catalog is a
+// target of, and the target's position. Only names the forward mapping
+// turns back into the Artifact Hub catalog are returned.
+func (p *catalogPattern) backward(artifactHubCatalog string) (string, int, bool) {
+	if p.reverse == "" {
+		return "", 0, false
+	}
+	for i, target := range p.targets {
+		m := target.match.FindStringSubmatch(artifactHubCatalog)
+		if m == nil {
+			continue
+		}
+
+		captures := make([]string, p.tekton.NumSubexp()+1)
+		for j, group := range target.groups {
+			captures[group] = m[j+1]
+		}
+		tektonCatalog := expandCaptures(p.reverse, captures)
+
+		if targets, ok := p.forward(tektonCatalog); ok && targets[i] == artifactHubCatalog {
+			return tektonCatalog, i, true
+		}
+	}
+	return "", 0, false
+}
+
+// globToRegex turns a glob where "*" matches one or more characters into a
+// regex capturing each "*", and the template rebuilding the name from the
+// captures.
+func globToRegex(glob string) (string, string) {
+	parts := strings.Split(glob, "*")
+	var expr, reverse strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			expr.WriteString("(.+)")
+			fmt.Fprintf(&reverse, "${%d}", i)
+		}
+		expr.WriteString(regexp.QuoteMeta(part))
+		reverse.WriteString(strings.ReplaceAll(part, "$", "$$"))
+	}
+	return expr.String(), reverse.String()
+}
+
+// globToTemplate numbers the "*"s of a glob target in order.
+func globToTemplate(glob string) string {
+	parts := strings.Split(strings.ReplaceAll(glob, "$", "$$"), "*")
+	var template strings.Builder
+	for i, part := range parts {
+		if i > 0 {
+			fmt.Fprintf(&template, "${%d}", i)
+		}
+		template.WriteString(part)
+	}
+	return template.String()
+}
+
+// regexTemplate returns the template rebuilding a name matched by a regex
+// made of literals and captures, or "" for other regexes.
+func regexTemplate(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+
+	var template strings.Builder
+	var walk func(*syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+		case syntax.OpLiteral:
+			template.WriteString(strings.ReplaceAll(string(re.Rune), "$", "$$"))
+		case syntax.OpCapture:
+			fmt.Fprintf(&template, "${%d}", re.Cap)
+		case syntax.OpBeginText, syntax.OpEndText, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpEmptyMatch:
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(re) {
+		return ""
+	}
+	return template.String()
+}
+
+// expandCaptures substitutes captures into a template of ${N} references.
+func expandCaptures(template string, captures []string) string {
+	return templateRefRegex.ReplaceAllStringFunc(template, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		group, _ := strconv.Atoi(strings.Trim(ref, "${}"))
+		return captures[group]
+	})
+}
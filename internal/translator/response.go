@@ -2,19 +2,29 @@ package translator
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"tekton-hub-proxy/internal/models"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
 )
 
+// defaultPlatform is the platform of resources not listing theirs.
+const defaultPlatform = "linux/amd64"
+
 type ResponseTranslator struct {
 	versionTranslator *VersionTranslator
+	kinds             *KindTable
+	categories        *CategoryTable
 }
 
-func NewResponseTranslator() *ResponseTranslator {
+func NewResponseTranslator(kinds *KindTable, versionTranslator *VersionTranslator, categories *CategoryTable) *ResponseTranslator {
 	return &ResponseTranslator{
-		versionTranslator: NewVersionTranslator(),
+		versionTranslator: versionTranslator,
+		kinds:             kinds,
+		categories:        categories,
 	}
 }
 
@@ -27,46 +37,47 @@ func (r *ResponseTranslator) ArtifactHubPackageToTektonResource(pkg *models.Arti
 		return nil, fmt.Errorf("failed to convert catalog name: %w", err)
 	}
 
-	// Extract kind from repository kind (e.g., "tekton-task" -> "task")
-	kind := r.extractKindFromRepoKind(pkg.Repository.Kind)
+	// Extract kind from repository kind (e.g., 7 -> "task")
+	kind, err := r.kinds.FromRepositoryKind(pkg.Repository.Kind)
+	if err != nil {
+		return nil, err
+	}
 
 	// Convert latest version
-	latestVersion, err := r.versionTranslator.ArtifactHubToTekton(pkg.Version)
+	available := AvailableVersionStrings(pkg)
+	latestVersion, err := r.versionTranslator.ArtifactHubToTektonAmong(pkg.Version, available)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert latest version: %w", err)
 	}
 
 	// Convert all versions
 	var versions []models.TektonHubVersionSummary
-	for i, version := range pkg.AvailableVersions {
-		tektonVersion, err := r.versionTranslator.ArtifactHubToTekton(version.Version)
+	for _, version := range pkg.AvailableVersions {
+		tektonVersion, err := r.versionTranslator.ArtifactHubToTektonAmong(version.Version, available)
 		if err != nil {
 			logrus.WithField("version", version.Version).Warn("Failed to convert version, skipping")
 			continue
 		}
 
 		versions = append(versions, models.TektonHubVersionSummary{
-			ID:      i + 1, // Generate sequential ID
+			ID:      r.GenerateVersionID(pkg.PackageID, version.Version),
 			Version: tektonVersion,
 		})
 	}
 
+	// Categories and platforms come from the manifest annotations
+	annotations := manifestAnnotations(pkg.Data.ManifestRaw)
+
 	// Build the resource
 	resource := &models.TektonHubResource{
-		ID:   r.generateResourceID(pkg.PackageID),
-		Name: pkg.Name,
-		Kind: kind,
-		Catalog: models.TektonHubCatalog{
-			ID:       r.generateCatalogID(pkg.Repository.Name),
-			Name:     tektonCatalog,
-			Provider: "github", // Default to github
-			Type:     r.getCatalogType(pkg.Repository.Official),
-			URL:      pkg.Repository.URL,
-		},
-		Categories:    r.convertKeywordsToCategories(pkg.Keywords),
+		ID:            r.generateResourceID(pkg.PackageID),
+		Name:          pkg.Name,
+		Kind:          kind,
+		Catalog:       catalogTranslator.Catalog(tektonCatalog, &pkg.Repository),
+		Categories:    r.categories.Classify(annotations["tekton.dev/categories"], pkg.Keywords),
 		Tags:          r.convertKeywordsToTags(pkg.Keywords),
-		Platforms:     []models.TektonHubPlatform{{ID: 1, Name: "linux/amd64"}}, // Default platform
-		Rating:        4.0,                                                      // Default rating since Artifact Hub doesn't provide this
+		Platforms:     r.platforms(annotations),
+		Rating:        4.0, // Default rating since Artifact Hub doesn't provide this
 		HubURLPath:    fmt.Sprintf("%s/%s/%s", tektonCatalog, kind, pkg.Name),
 		HubRawURLPath: fmt.Sprintf("/%s/%s/%s/raw", tektonCatalog, kind, pkg.Name),
 		Versions:      versions,
@@ -74,7 +85,7 @@ func (r *ResponseTranslator) ArtifactHubPackageToTektonResource(pkg *models.Arti
 
 	// Set latest version details
 	resource.LatestVersion = models.TektonHubResourceVersion{
-		ID:                  1,
+		ID:                  r.GenerateVersionID(pkg.PackageID, pkg.Version),
 		Version:             latestVersion,
 		DisplayName:         pkg.DisplayName,
 		Description:         pkg.Description,
@@ -86,11 +97,74 @@ func (r *ResponseTranslator) ArtifactHubPackageToTektonResource(pkg *models.Arti
 		HubURLPath:          resource.HubURLPath,
 		HubRawURLPath:       resource.HubRawURLPath,
 		Deprecated:          pkg.Deprecated,
+		Prerelease:          pkg.Prerelease,
 	}
 
 	return resource, nil
 }
 
+// ArtifactHubPackageToTektonVersions lists every available version of the
+// package, oldest first. Versions other than the fetched one link to the
+// proxy at baseURL since Artifact Hub only returns the content URL of the
+// fetched version. Their platforms and deprecation aren't known either and
+// are left unset rather than copied from the fetched version.
+func (r *ResponseTranslator) ArtifactHubPackageToTektonVersions(pkg *models.ArtifactHubPackage, catalogTranslator *CatalogTranslator, baseURL string) (*models.TektonHubVersionsResponse, error) {
+	resource, err := r.ArtifactHubPackageToTektonResource(pkg, catalogTranslator)
+	if err != nil {
+		return nil, err
+	}
+
+	available := AvailableVersionStrings(pkg)
+	sorted := make([]models.ArtifactHubVersion, len(pkg.AvailableVersions))
+	copy(sorted, pkg.AvailableVersions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		cmp, err := r.versionTranslator.CompareVersions(sorted[i].Version, sorted[j].Version)
+		return err == nil && cmp < 0
+	})
+
+	var versions []models.TektonHubResourceVersion
+	for _, version := range sorted {
+		tektonVersion, err := r.versionTranslator.ArtifactHubToTektonAmong(version.Version, available)
+		if err != nil {
+			logrus.WithField("version", version.Version).Warn("Failed to convert version, skipping")
+			continue
+		}
+
+		resourceURL := fmt.Sprintf("%s/v1/resource/%s/%s/%s/%s", baseURL, resource.Catalog.Name, resource.Kind, pkg.Name, tektonVersion)
+		entry := models.TektonHubResourceVersion{
+			ID:            r.GenerateVersionID(pkg.PackageID, version.Version),
+			Version:       tektonVersion,
+			RawURL:        resourceURL + "/raw",
+			WebURL:        resourceURL,
+			HubURLPath:    resource.HubURLPath,
+			HubRawURLPath: resource.HubRawURLPath,
+		}
+		if version.Version == pkg.Version {
+			// Details of the fetched version are known
+			entry = resource.LatestVersion
+			if entry.RawURL == "" {
+				entry.RawURL = resourceURL + "/raw"
+				entry.WebURL = resourceURL
+			}
+		}
+		entry.UpdatedAt = time.Unix(version.TS, 0)
+		entry.Prerelease = version.Prerelease
+		entry.ContainsSecurityUpdates = version.ContainsSecurityUpdates
+		if version.Version == pkg.Version {
+			resource.LatestVersion = entry
+		}
+
+		versions = append(versions, entry)
+	}
+
+	return &models.TektonHubVersionsResponse{
+		Data: models.TektonHubVersionsData{
+			Latest:   resource.LatestVersion,
+			Versions: versions,
+		},
+	}, nil
+}
+
 func (r *ResponseTranslator) ArtifactHubPackageToTektonYAML(pkg *models.ArtifactHubPackage) (*models.TektonHubYamlResponse, error) {
 	return &models.TektonHubYamlResponse{
 		Data: models.TektonHubYamlData{
@@ -129,62 +203,94 @@ func (r *ResponseTranslator) ArtifactHubSearchToTektonResources(search *models.A
 	}, nil
 }
 
-func (r *ResponseTranslator) extractKindFromRepoKind(kind int) string {
-	// Map Artifact Hub repository kinds to Tekton kinds
-	// This is a simplified mapping - in reality, you'd need to query Artifact Hub for kind mappings
-	switch kind {
-	case 12: // Tekton task
-		return "task"
-	case 13: // Tekton pipeline
-		return "pipeline"
-	default:
-		return "task" // Default to task
+// AvailableVersionStrings returns the available versions of a package.
+func AvailableVersionStrings(pkg *models.ArtifactHubPackage) []string {
+	available := make([]string, 0, len(pkg.AvailableVersions))
+	for _, v := range pkg.AvailableVersions {
+		available = append(available, v.Version)
 	}
+	return available
 }
 
-func (r *ResponseTranslator) generateResourceID(packageID string) int {
-	// Simple hash-based ID generation
-	hash := 0
-	for _, c := range packageID {
-		hash = hash*31 + int(c)
+// Kinds returns the kind table used by the translator.
+func (r *ResponseTranslator) Kinds() *KindTable {
+	return r.kinds
+}
+
+// GenerateResourceID returns the stable Tekton Hub ID of a package.
+func (r *ResponseTranslator) GenerateResourceID(packageID string) int {
+	return r.generateResourceID(packageID)
+}
+
+// GenerateVersionID returns the stable Tekton Hub ID of a package version.
+func (r *ResponseTranslator) GenerateVersionID(packageID, version string) int {
+	return r.generateResourceID(packageID + "@" + version)
+}
+
+// PlatformsFromManifest returns the platforms listed in the
+// tekton.dev/platforms annotation of a manifest. Like Tekton Hub, resources
+// without the annotation run on linux/amd64.
+func (r *ResponseTranslator) PlatformsFromManifest(manifestRaw string) []models.TektonHubPlatform {
+	return r.platforms(manifestAnnotations(manifestRaw))
+}
+
+func (r *ResponseTranslator) platforms(annotations map[string]string) []models.TektonHubPlatform {
+	var platforms []models.TektonHubPlatform
+	for _, name := range strings.Split(annotations["tekton.dev/platforms"], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		platforms = append(platforms, models.TektonHubPlatform{
+			ID:   r.generateCatalogID(name),
+			Name: name,
+		})
 	}
-	if hash < 0 {
-		hash = -hash
+	if len(platforms) == 0 {
+		platforms = []models.TektonHubPlatform{{ID: r.generateCatalogID(defaultPlatform), Name: defaultPlatform}}
 	}
-	return hash % 1000000 // Keep it reasonable
+	return platforms
 }
 
-func (r *ResponseTranslator) generateCatalogID(catalogName string) int {
-	// Simple hash-based ID generation for catalog
-	hash := 0
-	for _, c := range catalogName {
-		hash = hash*31 + int(c)
+// Categories returns the category taxonomy used by the translator.
+func (r *ResponseTranslator) Categories() *CategoryTable {
+	return r.categories
+}
+
+// manifestAnnotations returns the annotations of a Tekton resource manifest.
+func manifestAnnotations(manifestRaw string) map[string]string {
+	var manifest struct {
+		Metadata struct {
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
 	}
-	if hash < 0 {
-		hash = -hash
+	if manifestRaw != "" {
+		if err := yaml.Unmarshal([]byte(manifestRaw), &manifest); err != nil {
+			logrus.WithError(err).Debug("Failed to parse manifest annotations")
+		}
 	}
-	return hash % 1000 // Keep it reasonable
+	return manifest.Metadata.Annotations
 }
 
-func (r *ResponseTranslator) getCatalogType(official bool) string {
-	if official {
-		return "official"
-	}
-	return "community"
+func (r *ResponseTranslator) generateResourceID(packageID string) int {
+	return hashID(packageID, 1000000) // Keep it reasonable
 }
 
-func (r *ResponseTranslator) convertKeywordsToCategories(keywords []string) []models.TektonHubCategory {
-	var categories []models.TektonHubCategory
-	for i, keyword := range keywords {
-		if i >= 5 { // Limit to 5 categories
-			break
-		}
-		categories = append(categories, models.TektonHubCategory{
-			ID:   i + 1,
-			Name: keyword,
-		})
+func (r *ResponseTranslator) generateCatalogID(catalogName string) int {
+	return hashID(catalogName, 1000) // Keep it reasonable
+}
+
+// hashID returns a stable ID below limit for a name.
+func hashID(name string, limit int) int {
+	// Simple hash-based ID generation
+	hash := 0
+	for _, c := range name {
+		hash = hash*31 + int(c)
+	}
+	if hash < 0 {
+		hash = -hash
 	}
-	return categories
+	return hash % limit
 }
 
 func (r *ResponseTranslator) convertKeywordsToTags(keywords []string) []models.TektonHubTag {
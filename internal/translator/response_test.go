@@ -0,0 +1,97 @@
+package translator
+
+import (
+	"tekton-hub-proxy/internal/config"
+	"tekton-hub-proxy/internal/models"
+	"testing"
+)
+
+func TestArtifactHubPackageToTektonVersions(t *testing.T) {
+	kinds := DefaultKindTable()
+	catalogTranslator := NewCatalogTranslator([]config.CatalogMapping{
+		{TektonHub: "tekton", ArtifactHub: "tekton-catalog-tasks"},
+	}, kinds)
+	responseTranslator := NewResponseTranslator(kinds, NewVersionTranslator(), DefaultCategoryTable())
+
+	pkg := &models.ArtifactHubPackage{
+		PackageID:  "0b3c6e2a",
+		Name:       "git-clone",
+		Version:    "0.10.0",
+		ContentURL: "https://raw.githubusercontent.com/tektoncd/catalog/main/task/git-clone/0.10/git-clone.yaml",
+		Deprecated: true,
+		Repository: models.ArtifactHubRepository{Name: "tekton-catalog-tasks", Kind: 7},
+		AvailableVersions: []models.ArtifactHubVersion{
+			{Version: "0.10.0", TS: 1700000200, ContainsSecurityUpdates: true},
+			{Version: "0.9.0", TS: 1700000100},
+			{Version: "0.11.0-rc.1", TS: 1700000300, Prerelease: true},
+		},
+	}
+
+	response, err := responseTranslator.ArtifactHubPackageToTektonVersions(pkg, catalogTranslator, "http://proxy")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	versions := response.Data.Versions
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	if versions[0].Version != "0.9" || versions[1].Version != "0.10" || versions[2].Version != "0.11.0-rc.1" {
+		t.Errorf("unexpected version order: %s, %s, %s", versions[0].Version, versions[1].Version, versions[2].Version)
+	}
+
+	old := versions[0]
+	if old.RawURL != "http://proxy/v1/resource/tekton/task/git-clone/0.9/raw" || old.WebURL != "http://proxy/v1/resource/tekton/task/git-clone/0.9" {
+		t.Errorf("unexpected URLs for 0.9: %s %s", old.RawURL, old.WebURL)
+	}
+	if old.UpdatedAt.Unix() != 1700000100 || old.Deprecated || old.Platforms != nil {
+		t.Errorf("unexpected details for 0.9: %+v", old)
+	}
+	if versions[1].RawURL != pkg.ContentURL || !versions[1].ContainsSecurityUpdates || !versions[1].Deprecated {
+		t.Errorf("unexpected details for 0.10: %+v", versions[1])
+	}
+	if !versions[2].Prerelease {
+		t.Error("expected 0.11.0-rc.1 to be a prerelease")
+	}
+
+	// IDs are stable and distinct
+	if old.ID != responseTranslator.GenerateVersionID(pkg.PackageID, "0.9.0") || old.ID == versions[1].ID {
+		t.Errorf("unexpected version IDs: %d %d", old.ID, versions[1].ID)
+	}
+	if response.Data.Latest.ID != versions[1].ID || !response.Data.Latest.ContainsSecurityUpdates {
+		t.Errorf("expected latest to match 0.10, got %+v", response.Data.Latest)
+	}
+}
+
+func TestPlatformsFromManifest(t *testing.T) {
+	responseTranslator := NewResponseTranslator(DefaultKindTable(), NewVersionTranslator(), DefaultCategoryTable())
+
+	tests := []struct {
+		name     string
+		manifest string
+		expected []string
+	}{
+		{
+			name:     "annotation",
+			manifest: "metadata:\n  annotations:\n    tekton.dev/platforms: \"linux/amd64, linux/arm64,linux/s390x\"\n",
+			expected: []string{"linux/amd64", "linux/arm64", "linux/s390x"},
+		},
+		{name: "no annotation", manifest: "metadata:\n  name: git-clone\n", expected: []string{"linux/amd64"}},
+		{name: "no manifest", manifest: "", expected: []string{"linux/amd64"}},
+		{name: "invalid manifest", manifest: "metadata: [", expected: []string{"linux/amd64"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platforms := responseTranslator.PlatformsFromManifest(tt.manifest)
+			if len(platforms) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, platforms)
+			}
+			for i, platform := range platforms {
+				if platform.Name != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, platforms)
+				}
+			}
+		})
+	}
+}
@@ -9,14 +9,42 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Policies resolving a simplified x.y version against the versions a
+// package has.
+const (
+	// SimplifiedExactFirst picks x.y.0 when available, the highest x.y.z
+	// patch otherwise.
+	SimplifiedExactFirst = "exact_first"
+	// SimplifiedExact only picks x.y.0.
+	SimplifiedExact = "exact"
+	// SimplifiedHighestPatch always picks the highest x.y.z patch.
+	SimplifiedHighestPatch = "highest_patch"
+)
+
 type VersionTranslator struct {
-	semverRegex *regexp.Regexp
+	semverRegex      *regexp.Regexp
+	simplifiedPolicy string
 }
 
 func NewVersionTranslator() *VersionTranslator {
 	return &VersionTranslator{
-		semverRegex: regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`),
+		semverRegex:      regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`),
+		simplifiedPolicy: SimplifiedExactFirst,
+	}
+}
+
+// NewVersionTranslatorWithPolicy returns a translator resolving simplified
+// versions with the given policy, SimplifiedExactFirst when empty.
+func NewVersionTranslatorWithPolicy(simplifiedPolicy string) (*VersionTranslator, error) {
+	v := NewVersionTranslator()
+	switch simplifiedPolicy {
+	case "":
+	case SimplifiedExactFirst, SimplifiedExact, SimplifiedHighestPatch:
+		v.simplifiedPolicy = simplifiedPolicy
+	default:
+		return nil, fmt.Errorf("unknown simplified version policy %q", simplifiedPolicy)
 	}
+	return v, nil
 }
 
 func (v *VersionTranslator) TektonToArtifactHub(tektonVersion string) (string, error) {
@@ -120,6 +148,93 @@ func (v *VersionTranslator) ArtifactHubToTekton(artifactHubVersion string) (stri
 	return artifactHubVersion, nil
 }
 
+// ResolveAvailable returns the available version a requested Tekton version
+// names. Exact matches win, ignoring a "v" prefix, simplified x.y versions
+// fall back to their patches following the simplified version policy.
+func (v *VersionTranslator) ResolveAvailable(requested string, available []string) (string, error) {
+	requestedVersion, err := version.NewVersion(requested)
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", requested, ErrInvalidVersionConstraint)
+	}
+	simplified := v.isSimplifiedSemver(strings.TrimPrefix(requested, "v"))
+	segments := requestedVersion.Segments()
+
+	var exact, highest string
+	var highestVersion *version.Version
+	for _, candidate := range available {
+		candidateVersion, err := version.NewVersion(candidate)
+		if err != nil {
+			continue
+		}
+		if exact == "" && candidateVersion.Equal(requestedVersion) {
+			exact = candidate
+		}
+		if !simplified || candidateVersion.Prerelease() != "" {
+			continue
+		}
+		candidateSegments := candidateVersion.Segments()
+		if candidateSegments[0] != segments[0] || candidateSegments[1] != segments[1] {
+			continue
+		}
+		if highestVersion == nil || candidateVersion.GreaterThan(highestVersion) {
+			highest, highestVersion = candidate, candidateVersion
+		}
+	}
+
+	resolved := exact
+	switch {
+	case !simplified || v.simplifiedPolicy == SimplifiedExact:
+	case v.simplifiedPolicy == SimplifiedHighestPatch && highest != "":
+		resolved = highest
+	case resolved == "":
+		resolved = highest
+	}
+
+	if resolved == "" {
+		return "", fmt.Errorf("%q: %w", requested, ErrNoMatchingVersion)
+	}
+
+	if resolved != requested {
+		logrus.WithFields(logrus.Fields{
+			"translation_type": "version",
+			"direction":        "tekton_to_artifacthub",
+			"input":            requested,
+			"output":           resolved,
+			"policy":           v.simplifiedPolicy,
+			"status":           "resolved_available",
+		}).Debug("✅ Resolved version against available versions")
+	}
+	return resolved, nil
+}
+
+// ArtifactHubToTektonAmong converts an Artifact Hub version to the shortest
+// Tekton version resolving back to it among the available versions, so
+// round-trips never name a version that does not exist.
+func (v *VersionTranslator) ArtifactHubToTektonAmong(artifactHubVersion string, available []string) (string, error) {
+	if len(available) == 0 {
+		return v.ArtifactHubToTekton(artifactHubVersion)
+	}
+
+	ver, err := version.NewVersion(artifactHubVersion)
+	if err != nil {
+		return artifactHubVersion, nil
+	}
+
+	var candidates []string
+	segments := ver.Segments()
+	if ver.Prerelease() == "" && ver.Metadata() == "" {
+		candidates = append(candidates, fmt.Sprintf("%d.%d", segments[0], segments[1]))
+	}
+	candidates = append(candidates, strings.TrimPrefix(artifactHubVersion, "v"))
+
+	for _, candidate := range candidates {
+		if resolved, err := v.ResolveAvailable(candidate, available); err == nil && resolved == artifactHubVersion {
+			return candidate, nil
+		}
+	}
+	return artifactHubVersion, nil
+}
+
 func (v *VersionTranslator) isFullSemver(versionStr string) bool {
 	matches := v.semverRegex.FindStringSubmatch(versionStr)
 	return len(matches) >= 4 && matches[3] != ""
@@ -143,6 +258,26 @@ func (v *VersionTranslator) ValidateVersion(versionStr string) error {
 	return nil
 }
 
+// IsPrerelease reports whether the version is a prerelease.
+func (v *VersionTranslator) IsPrerelease(versionStr string) bool {
+	ver, err := version.NewVersion(versionStr)
+	return err == nil && ver.Prerelease() != ""
+}
+
+// SatisfiesMinVersion reports whether pipelinesVersion meets minVersion. An
+// unknown pipelines version or minimum is always satisfied.
+func (v *VersionTranslator) SatisfiesMinVersion(minVersion, pipelinesVersion string) bool {
+	if minVersion == "" || pipelinesVersion == "" {
+		return true
+	}
+	cmp, err := v.CompareVersions(minVersion, pipelinesVersion)
+	if err != nil {
+		logrus.WithField("min_version", minVersion).Debug("Invalid minimum pipelines version, ignoring")
+		return true
+	}
+	return cmp <= 0
+}
+
 func (v *VersionTranslator) CompareVersions(v1, v2 string) (int, error) {
 	ver1, err := version.NewVersion(v1)
 	if err != nil {
@@ -226,4 +226,115 @@ func TestVersionTranslator_CompareVersions(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestVersionTranslator_ResolveAvailable(t *testing.T) {
+	available := []string{"v0.1.0", "0.1.1", "0.1.2", "0.2.1", "0.2.3", "0.3.0-rc.1"}
+
+	tests := []struct {
+		name      string
+		policy    string
+		requested string
+		expected  string
+		hasError  bool
+	}{
+		{name: "exact first prefers x.y.0", policy: SimplifiedExactFirst, requested: "0.1", expected: "v0.1.0"},
+		{name: "exact first falls back to highest patch", policy: SimplifiedExactFirst, requested: "0.2", expected: "0.2.3"},
+		{name: "full version", policy: SimplifiedExactFirst, requested: "0.2.1", expected: "0.2.1"},
+		{name: "v prefix ignored", policy: SimplifiedExactFirst, requested: "0.1.0", expected: "v0.1.0"},
+		{name: "prerelease is not a patch", policy: SimplifiedExactFirst, requested: "0.3", hasError: true},
+		{name: "exact only", policy: SimplifiedExact, requested: "0.2", hasError: true},
+		{name: "exact only x.y.0", policy: SimplifiedExact, requested: "0.1", expected: "v0.1.0"},
+		{name: "highest patch", policy: SimplifiedHighestPatch, requested: "0.1", expected: "0.1.2"},
+		{name: "highest patch full version", policy: SimplifiedHighestPatch, requested: "0.1.1", expected: "0.1.1"},
+		{name: "unknown minor", policy: SimplifiedExactFirst, requested: "0.4", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator, err := NewVersionTranslatorWithPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := translator.ResolveAvailable(tt.requested, available)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("expected error but got %q", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestVersionTranslator_ArtifactHubToTektonAmong(t *testing.T) {
+	available := []string{"v0.1.0", "0.1.1", "0.1.2", "0.2.1", "0.2.3", "0.3.0-rc.1"}
+
+	// Every converted version resolves back to itself under every policy.
+	for _, policy := range []string{SimplifiedExactFirst, SimplifiedExact, SimplifiedHighestPatch} {
+		translator, err := NewVersionTranslatorWithPolicy(policy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, version := range available {
+			tektonVersion, err := translator.ArtifactHubToTektonAmong(version, available)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resolved, err := translator.ResolveAvailable(tektonVersion, available)
+			if err != nil || resolved != version {
+				t.Errorf("%s: %s converted to %s resolves to %q (%v)", policy, version, tektonVersion, resolved, err)
+			}
+		}
+	}
+
+	translator := NewVersionTranslator()
+	tests := map[string]string{
+		"v0.1.0":     "0.1",
+		"0.1.2":      "0.1.2",
+		"0.2.3":      "0.2",
+		"0.2.1":      "0.2.1",
+		"0.3.0-rc.1": "0.3.0-rc.1",
+	}
+	for version, expected := range tests {
+		if result, _ := translator.ArtifactHubToTektonAmong(version, available); result != expected {
+			t.Errorf("expected %s to convert to %q, got %q", version, expected, result)
+		}
+	}
+
+	if _, err := NewVersionTranslatorWithPolicy("newest"); err == nil {
+		t.Error("expected unknown policy to be rejected")
+	}
+}
+
+func TestVersionTranslator_SatisfiesMinVersion(t *testing.T) {
+	translator := NewVersionTranslator()
+
+	tests := []struct {
+		name             string
+		minVersion       string
+		pipelinesVersion string
+		expected         bool
+	}{
+		{name: "newer pipelines", minVersion: "0.38.0", pipelinesVersion: "0.50.1", expected: true},
+		{name: "same version", minVersion: "0.50", pipelinesVersion: "v0.50.0", expected: true},
+		{name: "older pipelines", minVersion: "0.50.0", pipelinesVersion: "0.45.2", expected: false},
+		{name: "no minimum", minVersion: "", pipelinesVersion: "0.45.2", expected: true},
+		{name: "unknown pipelines version", minVersion: "0.50.0", pipelinesVersion: "", expected: true},
+		{name: "invalid minimum", minVersion: "latest", pipelinesVersion: "0.45.2", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := translator.SatisfiesMinVersion(tt.minVersion, tt.pipelinesVersion); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}